@@ -0,0 +1,45 @@
+package cloudevent
+
+// EventHeaderSizeEstimate is the number of bytes ChunkEvents adds to the data length of each
+// event to account for its serialized header.
+const EventHeaderSizeEstimate = 512
+
+// GroupBySubject groups events by Subject. Events keep their relative order within a group.
+func GroupBySubject[A any](events []CloudEvent[A]) map[string][]CloudEvent[A] {
+	return GroupBy(events, func(ev CloudEvent[A]) string { return ev.Subject })
+}
+
+// GroupBy groups events by the key returned by keyFn. Events keep their relative order within a group.
+func GroupBy[A any, K comparable](events []CloudEvent[A], keyFn func(CloudEvent[A]) K) map[K][]CloudEvent[A] {
+	groups := make(map[K][]CloudEvent[A])
+	for _, ev := range events {
+		k := keyFn(ev)
+		groups[k] = append(groups[k], ev)
+	}
+	return groups
+}
+
+// ChunkEvents splits events, in order, into batches of at most maxCount events whose estimated
+// serialized size is at most maxBytes. The size of an event is estimated as its data length plus
+// EventHeaderSizeEstimate. An event larger than maxBytes on its own is placed in a batch by itself.
+// A maxCount or maxBytes of zero or less disables that limit.
+func ChunkEvents(events []RawEvent, maxCount int, maxBytes int64) [][]RawEvent {
+	var batches [][]RawEvent
+	var batch []RawEvent
+	var size int64
+	for _, ev := range events {
+		evSize := int64(len(ev.Data)) + EventHeaderSizeEstimate
+		full := maxCount > 0 && len(batch) >= maxCount
+		tooBig := maxBytes > 0 && size+evSize > maxBytes
+		if len(batch) > 0 && (full || tooBig) {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, ev)
+		size += evSize
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,65 @@
+package cloudevent_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupBySubject(t *testing.T) {
+	t.Parallel()
+
+	events := []cloudevent.RawEvent{
+		{CloudEventHeader: cloudevent.CloudEventHeader{Subject: "a", ID: "1"}},
+		{CloudEventHeader: cloudevent.CloudEventHeader{Subject: "b", ID: "2"}},
+		{CloudEventHeader: cloudevent.CloudEventHeader{Subject: "a", ID: "3", Type: cloudevent.TypeFingerprint}},
+	}
+	groups := cloudevent.GroupBySubject(events)
+	assert.Equal(t, map[string][]cloudevent.RawEvent{
+		"a": {events[0], events[2]},
+		"b": {events[1]},
+	}, groups)
+
+	byType := cloudevent.GroupBy(events, func(ev cloudevent.RawEvent) string { return ev.Type })
+	assert.Len(t, byType[""], 2)
+	assert.Len(t, byType[cloudevent.TypeFingerprint], 1)
+
+	assert.Empty(t, cloudevent.GroupBySubject[json.RawMessage](nil))
+}
+
+func TestChunkEvents(t *testing.T) {
+	t.Parallel()
+
+	sized := func(id string, dataLen int) cloudevent.RawEvent {
+		return cloudevent.RawEvent{CloudEventHeader: cloudevent.CloudEventHeader{ID: id}, Data: bytes.Repeat([]byte("1"), dataLen)}
+	}
+	ids := func(batches [][]cloudevent.RawEvent) [][]string {
+		var out [][]string
+		for _, b := range batches {
+			var batch []string
+			for _, ev := range b {
+				batch = append(batch, ev.ID)
+			}
+			out = append(out, batch)
+		}
+		return out
+	}
+	const h = cloudevent.EventHeaderSizeEstimate
+
+	events := []cloudevent.RawEvent{sized("1", 100), sized("2", 100), sized("3", 100), sized("4", 100), sized("5", 100)}
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, ids(cloudevent.ChunkEvents(events, 2, 0)), "count limit")
+	assert.Equal(t, [][]string{{"1", "2", "3"}, {"4", "5"}}, ids(cloudevent.ChunkEvents(events, 0, 3*(h+100))), "exact byte budget fits")
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, ids(cloudevent.ChunkEvents(events, 0, 3*(h+100)-1)), "one byte short")
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, ids(cloudevent.ChunkEvents(events, 2, 10*(h+100))), "count limit hit first")
+	assert.Equal(t, [][]string{{"1", "2", "3", "4", "5"}}, ids(cloudevent.ChunkEvents(events, 0, 0)), "no limits")
+
+	// An event larger than the budget goes alone, without splitting its neighbours' batch.
+	mixed := []cloudevent.RawEvent{sized("small1", 10), sized("huge", 10_000), sized("small2", 10), sized("small3", 10)}
+	assert.Equal(t, [][]string{{"small1"}, {"huge"}, {"small2", "small3"}}, ids(cloudevent.ChunkEvents(mixed, 10, 2*(h+10))))
+	assert.Equal(t, [][]string{{"huge"}}, ids(cloudevent.ChunkEvents(mixed[1:2], 10, 1)))
+
+	assert.Empty(t, cloudevent.ChunkEvents(nil, 10, 100))
+}
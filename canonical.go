@@ -0,0 +1,200 @@
+package cloudevent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalJSON returns a canonical encoding of ev for signing and hashing, following the JSON
+// Canonicalization Scheme (RFC 8785) so other implementations can reproduce it byte for byte:
+//
+//   - members of every object, including Extras and JSON data, are sorted by their UTF-16 code units;
+//   - there is no insignificant whitespace;
+//   - strings escape only '"', '\\' and control characters;
+//   - numbers are IEEE 754 doubles written the way ECMAScript does, except that integers beyond
+//     2^53-1, which a double cannot hold exactly, keep their digits so the encoding binds their
+//     exact value (RFC 8785 would round them; consumers must parse them as big integers);
+//   - time is in UTC with RFC3339Nano, and the signature attribute is omitted.
+//
+// The set of members and the representation of data, as "data" or "data_base64", are the same
+// as MarshalJSON's.
+func CanonicalJSON(ev RawEvent) ([]byte, error) {
+	ev.Signature = ""
+	ev.Time = ev.Time.UTC()
+	encoded, err := ev.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("cloudevent: canonical encoding: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, obj); err != nil {
+		return nil, fmt.Errorf("cloudevent: canonical encoding: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonical writes a value decoded with UseNumber in RFC 8785 form.
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		if isLargeInteger(v.String()) {
+			buf.WriteString(v.String())
+			return nil
+		}
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return err
+		}
+		s, err := canonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected %T", v)
+	}
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what RFC 8785 requires.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			buf.WriteRune(r)
+			i += size
+			continue
+		}
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+		i++
+	}
+	buf.WriteByte('"')
+}
+
+// maxSafeInteger is the largest integer a float64 and an ECMAScript number hold exactly, 2^53-1.
+const maxSafeInteger = 1<<53 - 1
+
+// isLargeInteger reports whether s is a JSON integer literal, without fraction or exponent,
+// whose magnitude exceeds maxSafeInteger.
+func isLargeInteger(s string) bool {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || strings.ContainsAny(digits, ".eE") {
+		return false
+	}
+	n, err := strconv.ParseUint(digits, 10, 64)
+	return err != nil || n > maxSafeInteger
+}
+
+// canonicalNumber formats f like ECMAScript's Number.prototype.toString, as RFC 8785 requires.
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("number %v has no JSON representation", f)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	var sign string
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+	// The shortest round-trip digits, as d.ddde±x.
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, err := strconv.Atoi(exp)
+	if err != nil {
+		return "", err
+	}
+	k, n := len(digits), e+1
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+	out := sign + digits[:1]
+	if k > 1 {
+		out += "." + digits[1:]
+	}
+	if n-1 > 0 {
+		return out + "e+" + strconv.Itoa(n-1), nil
+	}
+	return out + "e-" + strconv.Itoa(1-n), nil
+}
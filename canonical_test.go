@@ -0,0 +1,69 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestCanonicalJSON_Golden(t *testing.T) {
+	t.Parallel()
+
+	inputs, err := filepath.Glob(filepath.Join("testdata", "canonical", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".golden.json") {
+			continue
+		}
+		golden := strings.TrimSuffix(input, ".json") + ".golden.json"
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := os.ReadFile(input)
+			require.NoError(t, err)
+			var ev cloudevent.RawEvent
+			require.NoError(t, json.Unmarshal(raw, &ev))
+			got, err := cloudevent.CanonicalJSON(ev)
+			require.NoError(t, err)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, got, 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+			assert.True(t, json.Valid(got))
+
+			// Canonicalizing is idempotent once the signature has been dropped.
+			var again cloudevent.RawEvent
+			require.NoError(t, json.Unmarshal(got, &again))
+			gotAgain, err := cloudevent.CanonicalJSON(again)
+			require.NoError(t, err)
+			assert.Equal(t, string(got), string(gotAgain))
+		})
+	}
+}
+
+func TestCanonicalJSON_OrderIndependent(t *testing.T) {
+	t.Parallel()
+
+	a := `{"id":"1","type":"t","source":"s","time":"2024-06-01T12:00:00Z","x":1,"y":{"b":1,"a":2},"data":{"k":1,"j":2}}`
+	b := `{"data":{"j":2,"k":1},"y":{"a":2,"b":1},"x":1.0,"time":"2024-06-01T08:00:00-04:00","source":"s","type":"t","id":"1"}`
+	var evA, evB cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal([]byte(a), &evA))
+	require.NoError(t, json.Unmarshal([]byte(b), &evB))
+	gotA, err := cloudevent.CanonicalJSON(evA)
+	require.NoError(t, err)
+	gotB, err := cloudevent.CanonicalJSON(evB)
+	require.NoError(t, err)
+	assert.Equal(t, string(gotA), string(gotB))
+}
@@ -0,0 +1,235 @@
+package cloudevent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Extension attributes set on the chunk events produced by ChunkEvent.
+const (
+	// ChunkGroupExtraKey holds the ID of the original event, shared by all of its chunks.
+	ChunkGroupExtraKey = "chunkgroup"
+	// ChunkIndexExtraKey holds the zero-based position of the chunk.
+	ChunkIndexExtraKey = "chunkindex"
+	// ChunkTotalExtraKey holds the number of chunks in the group.
+	ChunkTotalExtraKey = "chunktotal"
+	// ChunkDigestExtraKey holds the hex SHA-256 of the original event's data.
+	ChunkDigestExtraKey = "chunkdigest"
+	// ChunkDataContentTypeExtraKey holds the original event's datacontenttype.
+	ChunkDataContentTypeExtraKey = "chunkdatacontenttype"
+)
+
+// ChunkDataContentType is the datacontenttype of chunk events. Chunk data is an arbitrary
+// byte range of the original data, so it is carried as data_base64.
+const ChunkDataContentType = ContentTypeOctetStream
+
+// maxChunkTotal caps the number of chunks a group may declare.
+const maxChunkTotal = 1 << 20
+
+// ErrChunkBufferFull is returned by Reassembler.Add when buffering a chunk would exceed MaxBytes.
+var ErrChunkBufferFull = errors.New("cloudevent: chunk buffer full")
+
+// ChunkEvent splits ev into events carrying at most maxChunkSize bytes of data each.
+// Every chunk keeps the original header except for its ID, which becomes "<id>-<index>", and its
+// datacontenttype, which becomes ChunkDataContentType. The original ID, datacontenttype and the
+// chunk position are recorded in the chunk extension attributes, so chunks can be stored and
+// transported as ordinary events. An event whose data already fits is returned unchanged.
+func ChunkEvent(ev RawEvent, maxChunkSize int) ([]RawEvent, error) {
+	if maxChunkSize <= 0 {
+		return nil, fmt.Errorf("cloudevent: chunk size must be positive, got %d", maxChunkSize)
+	}
+	if ev.ID == "" {
+		return nil, errors.New("cloudevent: cannot chunk an event without an ID")
+	}
+	if len(ev.Data) <= maxChunkSize {
+		return []RawEvent{ev}, nil
+	}
+
+	sum := sha256.Sum256(ev.Data)
+	digest := hex.EncodeToString(sum[:])
+	total := (len(ev.Data) + maxChunkSize - 1) / maxChunkSize
+	chunks := make([]RawEvent, 0, total)
+	for i := range total {
+		chunk := RawEvent{CloudEventHeader: ev.CloudEventHeader}
+		chunk.ID = ev.ID + "-" + strconv.Itoa(i)
+		chunk.DataContentType = ChunkDataContentType
+		chunk.Extras = maps.Clone(ev.Extras)
+		if chunk.Extras == nil {
+			chunk.Extras = make(map[string]any, 5)
+		}
+		chunk.Extras[ChunkGroupExtraKey] = ev.ID
+		chunk.Extras[ChunkIndexExtraKey] = i
+		chunk.Extras[ChunkTotalExtraKey] = total
+		chunk.Extras[ChunkDigestExtraKey] = digest
+		if ev.DataContentType != "" {
+			chunk.Extras[ChunkDataContentTypeExtraKey] = ev.DataContentType
+		}
+		chunk.Data = ev.Data[i*maxChunkSize : min((i+1)*maxChunkSize, len(ev.Data))]
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// IsChunk reports whether ev is a chunk produced by ChunkEvent.
+func IsChunk(ev RawEvent) bool {
+	_, ok := ev.Extras[ChunkGroupExtraKey]
+	return ok
+}
+
+// Reassembler collects chunks produced by ChunkEvent and rebuilds the original events.
+// It is safe for concurrent use. The zero value buffers without limit and never expires groups.
+type Reassembler struct {
+	// MaxBytes bounds the chunk data buffered across all incomplete groups. Zero means no limit.
+	// Every chunk carries at least one byte, so groups declaring more chunks than MaxBytes are
+	// rejected up front.
+	MaxBytes int
+	// TTL is how long an incomplete group is kept after its first chunk arrives. Zero means forever.
+	TTL time.Duration
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	groups map[string]*chunkGroup
+	size   int
+}
+
+type chunkGroup struct {
+	header  CloudEventHeader
+	digest  string
+	chunks  map[int][]byte
+	total   int
+	size    int
+	created time.Time
+}
+
+// Add buffers chunk and returns the original event once every chunk of its group has arrived.
+// Chunks may arrive in any order; a repeated chunk is ignored. The reassembled data is checked
+// against the group digest before it is returned. Events that are not chunks are returned as is.
+func (r *Reassembler) Add(chunk RawEvent) (RawEvent, bool, error) {
+	if !IsChunk(chunk) {
+		return chunk, true, nil
+	}
+	group, index, total, digest, err := chunkInfo(chunk)
+	if err != nil {
+		return RawEvent{}, false, err
+	}
+	if r.MaxBytes > 0 && total > r.MaxBytes {
+		return RawEvent{}, false, ErrChunkBufferFull
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	r.expire(now())
+
+	g, ok := r.groups[group]
+	if !ok {
+		g = &chunkGroup{digest: digest, chunks: make(map[int][]byte), total: total, created: now()}
+		if r.groups == nil {
+			r.groups = make(map[string]*chunkGroup)
+		}
+		r.groups[group] = g
+	} else if g.total != total || g.digest != digest {
+		return RawEvent{}, false, fmt.Errorf("cloudevent: chunk %d of group %q does not match earlier chunks", index, group)
+	}
+	if _, dup := g.chunks[index]; dup {
+		return RawEvent{}, false, nil
+	}
+	if r.MaxBytes > 0 && r.size+len(chunk.Data) > r.MaxBytes {
+		if len(g.chunks) == 0 {
+			delete(r.groups, group)
+		}
+		return RawEvent{}, false, ErrChunkBufferFull
+	}
+	if index == 0 {
+		g.header = chunk.CloudEventHeader
+	}
+	g.chunks[index] = chunk.Data
+	g.size += len(chunk.Data)
+	r.size += len(chunk.Data)
+	if len(g.chunks) < total {
+		return RawEvent{}, false, nil
+	}
+
+	delete(r.groups, group)
+	r.size -= g.size
+	data := make([]byte, 0, g.size)
+	for i := range total {
+		data = append(data, g.chunks[i]...)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != g.digest {
+		return RawEvent{}, false, fmt.Errorf("cloudevent: digest mismatch for chunk group %q", group)
+	}
+
+	ev := RawEvent{CloudEventHeader: g.header, Data: data}
+	ev.ID = group
+	ev.DataContentType, _ = ev.Extras[ChunkDataContentTypeExtraKey].(string)
+	ev.Extras = maps.Clone(ev.Extras)
+	for _, key := range []string{ChunkGroupExtraKey, ChunkIndexExtraKey, ChunkTotalExtraKey, ChunkDigestExtraKey, ChunkDataContentTypeExtraKey} {
+		delete(ev.Extras, key)
+	}
+	if len(ev.Extras) == 0 {
+		ev.Extras = nil
+	}
+	return ev, true, nil
+}
+
+// Pending returns the number of incomplete groups being buffered.
+func (r *Reassembler) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.groups)
+}
+
+// expire drops groups older than TTL.
+func (r *Reassembler) expire(now time.Time) {
+	if r.TTL <= 0 {
+		return
+	}
+	for id, g := range r.groups {
+		if now.Sub(g.created) > r.TTL {
+			r.size -= g.size
+			delete(r.groups, id)
+		}
+	}
+}
+
+// chunkInfo reads and validates the chunk extension attributes.
+func chunkInfo(chunk RawEvent) (group string, index, total int, digest string, err error) {
+	group, _ = chunk.Extras[ChunkGroupExtraKey].(string)
+	digest, _ = chunk.Extras[ChunkDigestExtraKey].(string)
+	if group == "" || digest == "" {
+		return "", 0, 0, "", errors.New("cloudevent: chunk is missing its group or digest")
+	}
+	index, ok := extraInt(chunk.Extras[ChunkIndexExtraKey])
+	if !ok {
+		return "", 0, 0, "", fmt.Errorf("cloudevent: invalid %s", ChunkIndexExtraKey)
+	}
+	total, ok = extraInt(chunk.Extras[ChunkTotalExtraKey])
+	if !ok || total <= 0 || total > maxChunkTotal || index < 0 || index >= total {
+		return "", 0, 0, "", fmt.Errorf("cloudevent: invalid chunk %d of %d", index, total)
+	}
+	if len(chunk.Data) == 0 {
+		return "", 0, 0, "", fmt.Errorf("cloudevent: chunk %d of group %q has no data", index, group)
+	}
+	return group, index, total, digest, nil
+}
+
+// extraInt converts an integer extra to int. Decoded JSON numbers arrive as float64.
+func extraInt(v any) (int, bool) {
+	i, ok := extraInt64(v)
+	if !ok || int64(int(i)) != i {
+		return 0, false
+	}
+	return int(i), true
+}
@@ -0,0 +1,168 @@
+package cloudevent_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oversizedEvent() cloudevent.RawEvent {
+	return cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			SpecVersion:     cloudevent.SpecVersion,
+			ID:              "dump-1",
+			Type:            cloudevent.TypeStatus,
+			Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+			Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+			Time:            time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			DataContentType: "application/json",
+			Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+			Extras:          map[string]any{"region": "eu"},
+		},
+		Data: json.RawMessage(`{"dump":"` + string(bytes.Repeat([]byte("x"), 100)) + `"}`),
+	}
+}
+
+// roundTrip sends chunks through JSON as a transport would.
+func roundTrip(t *testing.T, chunks []cloudevent.RawEvent) []cloudevent.RawEvent {
+	t.Helper()
+	out := make([]cloudevent.RawEvent, len(chunks))
+	for i, c := range chunks {
+		b, err := json.Marshal(c)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &out[i]))
+	}
+	return out
+}
+
+func TestChunkEvent(t *testing.T) {
+	t.Parallel()
+
+	ev := oversizedEvent()
+	chunks, err := cloudevent.ChunkEvent(ev, 32)
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+	for i, c := range chunks {
+		assert.True(t, cloudevent.IsChunk(c))
+		assert.Equal(t, ev.Subject, c.Subject)
+		assert.Equal(t, ev.Type, c.Type)
+		assert.NotEqual(t, ev.ID, c.ID, "chunks need distinct keys to be stored as events")
+		assert.Equal(t, i, c.Extras[cloudevent.ChunkIndexExtraKey])
+		assert.LessOrEqual(t, len(c.Data), 32)
+	}
+	assert.Equal(t, map[string]any{"region": "eu"}, ev.Extras, "original extras are not modified")
+
+	small, err := cloudevent.ChunkEvent(ev, len(ev.Data))
+	require.NoError(t, err)
+	assert.Equal(t, []cloudevent.RawEvent{ev}, small)
+
+	_, err = cloudevent.ChunkEvent(ev, 0)
+	require.Error(t, err)
+}
+
+func TestReassembler_OutOfOrderAndDuplicates(t *testing.T) {
+	t.Parallel()
+
+	ev := oversizedEvent()
+	chunks, err := cloudevent.ChunkEvent(ev, 32)
+	require.NoError(t, err)
+	chunks = roundTrip(t, chunks)
+
+	var r cloudevent.Reassembler
+	for _, i := range []int{2, 0, 2, 3, 0} {
+		_, done, err := r.Add(chunks[i])
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+	assert.Equal(t, 1, r.Pending())
+
+	got, done, err := r.Add(chunks[1])
+	require.NoError(t, err)
+	require.True(t, done)
+	assert.Equal(t, ev.CloudEventHeader, got.CloudEventHeader)
+	assert.Equal(t, []byte(ev.Data), []byte(got.Data))
+	assert.Equal(t, 0, r.Pending())
+
+	// Plain events pass straight through.
+	got, done, err = r.Add(ev)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, ev, got)
+}
+
+func TestReassembler_Expiry(t *testing.T) {
+	t.Parallel()
+
+	chunks, err := cloudevent.ChunkEvent(oversizedEvent(), 32)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := cloudevent.Reassembler{TTL: time.Minute, Now: func() time.Time { return now }}
+	for _, c := range chunks[:3] {
+		_, done, err := r.Add(c)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	// The incomplete group expires, so the last chunk starts a new group instead of completing it.
+	now = now.Add(2 * time.Minute)
+	_, done, err := r.Add(chunks[3])
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 1, r.Pending())
+}
+
+func TestReassembler_Limits(t *testing.T) {
+	t.Parallel()
+
+	ev := oversizedEvent()
+	chunks, err := cloudevent.ChunkEvent(ev, 32)
+	require.NoError(t, err)
+
+	r := cloudevent.Reassembler{MaxBytes: 64}
+	for _, c := range chunks[:2] {
+		_, _, err := r.Add(c)
+		require.NoError(t, err)
+	}
+	_, _, err = r.Add(chunks[2])
+	require.ErrorIs(t, err, cloudevent.ErrChunkBufferFull)
+
+	// A chunk whose data was altered fails the digest check.
+	var tampered cloudevent.Reassembler
+	chunks[1].Data = bytes.Repeat([]byte("y"), len(chunks[1].Data))
+	for _, c := range chunks[:3] {
+		_, _, err := tampered.Add(c)
+		require.NoError(t, err)
+	}
+	_, _, err = tampered.Add(chunks[3])
+	require.Error(t, err)
+	assert.Equal(t, 0, tampered.Pending())
+}
+
+func TestReassembler_HostileTotal(t *testing.T) {
+	t.Parallel()
+
+	chunks, err := cloudevent.ChunkEvent(oversizedEvent(), 32)
+	require.NoError(t, err)
+	hostile := chunks[0]
+	hostile.Extras = maps.Clone(chunks[0].Extras)
+	hostile.Extras[cloudevent.ChunkTotalExtraKey] = float64(1 << 40)
+
+	// The declared total is rejected before anything is allocated for the group.
+	var unbounded cloudevent.Reassembler
+	_, _, err = unbounded.Add(hostile)
+	require.Error(t, err)
+	assert.Equal(t, 0, unbounded.Pending())
+
+	hostile.Extras[cloudevent.ChunkTotalExtraKey] = float64(1000)
+	bounded := cloudevent.Reassembler{MaxBytes: 512}
+	_, _, err = bounded.Add(hostile)
+	require.ErrorIs(t, err, cloudevent.ErrChunkBufferFull)
+	assert.Equal(t, 0, bounded.Pending())
+}
@@ -13,6 +13,10 @@ import (
 const (
 	// TableName is the name of the table in Clickhouse.
 	TableName = "cloud_event"
+	// LatestTableName is the name of the table holding only the newest row per
+	// subject, event type and data version. It is fed by a materialized view on
+	// TableName and must be read with FINAL to collapse unmerged rows.
+	LatestTableName = "cloud_event_latest"
 	// SubjectColumn is the name of the subject column in Clickhouse.
 	SubjectColumn = "subject"
 	// TimestampColumn is the name of the timestamp column in Clickhouse.
@@ -21,7 +25,9 @@ const (
 	TypeColumn = "event_type"
 	// IDColumn is the name of the ID column in Clickhouse.
 	IDColumn = "id"
-	// SourceColumn is the name of the source column in Clickhouse.
+	// SourceColumn is the name of the source column in Clickhouse. Queries that
+	// filter on source and TimestampColumn without a subject are served by the
+	// proj_source_time projection, which ClickHouse picks automatically.
 	SourceColumn = "source"
 	// ProducerColumn is the name of the producer column in Clickhouse.
 	ProducerColumn = "producer"
@@ -39,6 +45,11 @@ const (
 	// dimo.tombstone events it holds the id of the event, usually an attestation,
 	// being tombstoned; empty for all other event types.
 	VoidsIDColumn = "voids_id"
+	// SignatureColumn is the name of the nullable signature column in Clickhouse.
+	// It is NULL for unsigned events. Rows written before the column existed are
+	// also NULL and keep the signature in ExtrasColumn, where
+	// cloudevent.RestoreNonColumnFields finds it.
+	SignatureColumn = "signature"
 
 	// InsertStmt is the SQL statement for inserting a row into Clickhouse.
 	InsertStmt = "INSERT INTO " + TableName + " (" +
@@ -53,8 +64,9 @@ const (
 		ExtrasColumn + ", " +
 		IndexKeyColumn + ", " +
 		DataIndexKeyColumn + ", " +
-		VoidsIDColumn +
-		") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		VoidsIDColumn + ", " +
+		SignatureColumn +
+		") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 	// hexChars contains the characters used for hex representation
 	hexChars = "0123456789abcdef"
@@ -83,15 +95,21 @@ func StoredEventToSlice(stored *cloudevent.StoredEvent, indexKey string) []any {
 }
 
 func cloudEventToSlice(event *cloudevent.CloudEventHeader, indexKey, dataIndexKey, voidsID string) []any {
-	// Add non-column fields to extras
+	// Add non-column fields to extras; the signature has its own column here.
 	extras := cloudevent.AddNonColumnFieldsToExtras(event)
+	delete(extras, "signature")
 
 	var jsonExtra []byte
-	if extras == nil {
+	if len(extras) == 0 {
 		jsonExtra = []byte("{}")
 	} else {
 		jsonExtra, _ = json.Marshal(extras)
 	}
+	var signature *string
+	if event.Signature != "" {
+		sig := event.Signature
+		signature = &sig
+	}
 	return []any{
 		event.Subject,
 		event.Time,
@@ -105,20 +123,23 @@ func cloudEventToSlice(event *cloudevent.CloudEventHeader, indexKey, dataIndexKe
 		indexKey,
 		dataIndexKey,
 		voidsID,
+		signature,
 	}
 }
 
 // UnmarshalCloudEventSlice unmarshals a byte slice into an array of any for Clickhouse insertion.
+// Slices without the trailing signature element, as marshaled before the signature column
+// existed, are accepted and get a nil signature.
 func UnmarshalCloudEventSlice(jsonArray []byte) ([]any, error) {
 	var rawSlice []json.RawMessage
 	if err := json.Unmarshal(jsonArray, &rawSlice); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal cloud event slice: %w", err)
 	}
-	if len(rawSlice) != 12 {
+	if len(rawSlice) != 12 && len(rawSlice) != 13 {
 		return nil, fmt.Errorf("invalid cloud event slice length: %d", len(rawSlice))
 	}
 
-	// Column order: subject, timestamp, eventType, id, source, producer, dataContentType, dataVersion, extras, indexKey, dataIndexKey, voidsID
+	// Column order: subject, timestamp, eventType, id, source, producer, dataContentType, dataVersion, extras, indexKey, dataIndexKey, voidsID, signature
 	var (
 		subject         string
 		timestamp       time.Time
@@ -132,6 +153,7 @@ func UnmarshalCloudEventSlice(jsonArray []byte) ([]any, error) {
 		indexKey        string
 		dataIndexKey    string
 		voidsID         string
+		signature       *string
 	)
 	unmarshal := func(i int, name string, ptr any) error {
 		if err := json.Unmarshal(rawSlice[i], ptr); err != nil {
@@ -175,7 +197,12 @@ func UnmarshalCloudEventSlice(jsonArray []byte) ([]any, error) {
 	if err := unmarshal(11, "voids id", &voidsID); err != nil {
 		return nil, err
 	}
-	return []any{subject, timestamp, eventType, id, source, producer, dataContentType, dataVersion, extras, indexKey, dataIndexKey, voidsID}, nil
+	if len(rawSlice) == 13 {
+		if err := unmarshal(12, "signature", &signature); err != nil {
+			return nil, err
+		}
+	}
+	return []any{subject, timestamp, eventType, id, source, producer, dataContentType, dataVersion, extras, indexKey, dataIndexKey, voidsID, signature}, nil
 }
 
 // CloudEventToObjectKey generates a unique key for storing cloud events.
@@ -184,8 +211,22 @@ func CloudEventToObjectKey(event *cloudevent.CloudEventHeader) string {
 	if event == nil {
 		return ""
 	}
-	key := event.Key()
+	return objectKey(event.Key())
+}
+
+// LegacyCloudEventToObjectKey returns the object key earlier versions generated from
+// CloudEventHeader.LegacyKey, which truncated the event time to whole seconds. It differs from
+// CloudEventToObjectKey only for events with sub-second times; readers looking up such an event
+// by header should try CloudEventToObjectKey first and then this key.
+func LegacyCloudEventToObjectKey(event *cloudevent.CloudEventHeader) string {
+	if event == nil {
+		return ""
+	}
+	return objectKey(event.LegacyKey())
+}
 
+// objectKey prefixes key with a hex digit derived from its hash.
+func objectKey(key string) string {
 	hash := xxhash.Sum64String(key)
 	firstDigit := hash >> 60
 
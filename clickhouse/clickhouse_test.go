@@ -25,6 +25,7 @@ func TestCloudEventToSlice(t *testing.T) {
 		DataContentType: "application/json",
 		DataVersion:     "v1",
 		DataSchema:      "https://example.com/schema",
+		Signature:       "0xdeadbeef",
 		Extras: map[string]any{
 			"extra1": "value1",
 			"extra2": 123,
@@ -33,7 +34,7 @@ func TestCloudEventToSlice(t *testing.T) {
 
 	// Test CloudEventToSlice
 	slice := CloudEventToSlice(event)
-	require.Len(t, slice, 12)
+	require.Len(t, slice, 13)
 
 	// Verify the order and values of the slice
 	assert.Equal(t, event.Subject, slice[0])
@@ -53,6 +54,7 @@ func TestCloudEventToSlice(t *testing.T) {
 	assert.Equal(t, float64(123), extras["extra2"])
 	assert.Equal(t, "https://example.com/schema", extras["dataschema"])
 	assert.NotContains(t, extras, "specversion", "specversion is not stored in extras")
+	assert.NotContains(t, extras, "signature", "signature has its own column")
 
 	// Verify index key
 	expectedKey := CloudEventToObjectKey(event)
@@ -63,6 +65,10 @@ func TestCloudEventToSlice(t *testing.T) {
 
 	// voids_id is empty for non-tombstone events
 	assert.Equal(t, "", slice[11])
+
+	// signature is stored in its own column
+	require.NotNil(t, slice[12])
+	assert.Equal(t, event.Signature, *slice[12].(*string))
 }
 
 func TestCloudEventToSliceWithKey(t *testing.T) {
@@ -88,7 +94,7 @@ func TestCloudEventToSliceWithKey(t *testing.T) {
 
 	customKey := "custom-key"
 	slice := CloudEventToSliceWithKey(event, customKey)
-	require.Len(t, slice, 12)
+	require.Len(t, slice, 13)
 
 	// Verify the order and values of the slice
 	assert.Equal(t, event.Subject, slice[0])
@@ -117,6 +123,23 @@ func TestCloudEventToSliceWithKey(t *testing.T) {
 
 	// voids_id is empty for non-tombstone events
 	assert.Equal(t, "", slice[11])
+
+	// signature is NULL for unsigned events
+	assert.Nil(t, slice[12])
+}
+
+func TestCloudEventToSliceSignatureOnly(t *testing.T) {
+	t.Parallel()
+
+	event := &cloudevent.CloudEventHeader{
+		ID:        "test-id",
+		Subject:   "test-subject",
+		Signature: "0xdeadbeef",
+	}
+	slice := CloudEventToSlice(event)
+	require.Len(t, slice, 13)
+	assert.Equal(t, "{}", slice[8], "extras must be empty when the signature is the only non-column field")
+	assert.Equal(t, "0xdeadbeef", *slice[12].(*string))
 }
 
 func TestStoredEventToSlice(t *testing.T) {
@@ -141,7 +164,7 @@ func TestStoredEventToSlice(t *testing.T) {
 	}
 
 	slice := StoredEventToSlice(stored, "bundle/key#7")
-	require.Len(t, slice, 12)
+	require.Len(t, slice, 13)
 
 	assert.Equal(t, stored.Subject, slice[0])
 	assert.Equal(t, "bundle/key#7", slice[9])
@@ -158,6 +181,7 @@ func TestUnmarshalCloudEventSlice(t *testing.T) {
 	t.Parallel()
 
 	now := time.Now().UTC().Truncate(time.Millisecond)
+	signature := "0xdeadbeef"
 	expectedSlice := []any{
 		"test-subject",
 		now,
@@ -171,6 +195,7 @@ func TestUnmarshalCloudEventSlice(t *testing.T) {
 		"test-key",
 		"test-data-key",
 		"test-voids-id",
+		&signature,
 	}
 
 	// Marshal the slice to JSON
@@ -182,6 +207,13 @@ func TestUnmarshalCloudEventSlice(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expectedSlice, slice)
 
+	// Slices marshaled before the signature column existed get a nil signature
+	jsonData, err = json.Marshal(expectedSlice[:12])
+	require.NoError(t, err)
+	slice, err = UnmarshalCloudEventSlice(jsonData)
+	require.NoError(t, err)
+	assert.Equal(t, append(expectedSlice[:12:12], (*string)(nil)), slice)
+
 	// Test invalid JSON
 	_, err = UnmarshalCloudEventSlice([]byte("invalid json"))
 	assert.Error(t, err)
@@ -229,6 +261,26 @@ func TestCloudEventToObjectKey(t *testing.T) {
 	assert.NotEqual(t, key1, key2)
 }
 
+func TestLegacyCloudEventToObjectKey(t *testing.T) {
+	t.Parallel()
+
+	event := &cloudevent.CloudEventHeader{
+		ID:      "test-id",
+		Source:  "test-source",
+		Subject: "test-subject",
+		Time:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Type:    "test.type",
+	}
+	assert.Equal(t, CloudEventToObjectKey(event), LegacyCloudEventToObjectKey(event))
+
+	event.Time = event.Time.Add(time.Millisecond)
+	legacy := LegacyCloudEventToObjectKey(event)
+	assert.NotEqual(t, CloudEventToObjectKey(event), legacy)
+	assert.Equal(t, event.LegacyKey(), legacy[1:])
+	assert.Contains(t, legacy, "2024-06-01T12:00:00Z")
+	assert.Empty(t, LegacyCloudEventToObjectKey(nil))
+}
+
 func TestAddNonColumnFieldsToExtras(t *testing.T) {
 	t.Parallel()
 
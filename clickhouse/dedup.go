@@ -0,0 +1,57 @@
+package clickhouse
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+
+	chgo "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// InsertDeduplicationSettings returns the settings that make ClickHouse drop a repeated insert
+// of the row with the given index_key. The token is the index key itself, so it only identifies
+// single-row inserts; a retried batch needs one token derived from all of its rows, see
+// BatchDeduplicationToken.
+//
+// insert_deduplication_token requires ClickHouse 22.2 or later, and non-replicated tables only
+// honour it within the table's non_replicated_deduplication_window, which the migrations set.
+// Older servers reject the unknown setting, so callers targeting them should not use it.
+func InsertDeduplicationSettings(indexKey string) chgo.Settings {
+	return chgo.Settings{
+		"insert_deduplicate":         1,
+		"insert_deduplication_token": indexKey,
+	}
+}
+
+// WithInsertDeduplication returns a context whose inserts carry InsertDeduplicationSettings for indexKey.
+// It replaces any settings previously attached to ctx with clickhouse.WithSettings; merge
+// InsertDeduplicationSettings into those settings instead when both are needed.
+func WithInsertDeduplication(ctx context.Context, indexKey string) context.Context {
+	return chgo.Context(ctx, chgo.WithSettings(InsertDeduplicationSettings(indexKey)))
+}
+
+// BatchDeduplicationToken returns an insert_deduplication_token for a batch insert of the rows
+// with the given index keys. The token is a digest of the sorted keys, so it does not depend on
+// the order the keys are passed in.
+//
+// ClickHouse splits an insert into one block per partition and suffixes the token for each
+// block, so a retry is only recognized when it resends the same rows in the same order.
+// Like single-row tokens, batch tokens are only remembered within the deduplication window.
+func BatchDeduplicationToken(indexKeys []string) string {
+	sorted := slices.Clone(indexKeys)
+	slices.Sort(sorted)
+	h := sha256.New()
+	for _, key := range sorted {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithBatchInsertDeduplication returns a context whose inserts carry the deduplication settings
+// for a batch with the given index keys. Like WithInsertDeduplication, it replaces any settings
+// previously attached to ctx.
+func WithBatchInsertDeduplication(ctx context.Context, indexKeys []string) context.Context {
+	return WithInsertDeduplication(ctx, BatchDeduplicationToken(indexKeys))
+}
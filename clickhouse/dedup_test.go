@@ -0,0 +1,29 @@
+package clickhouse
+
+import (
+	"testing"
+
+	chgo "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertDeduplicationSettings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, chgo.Settings{
+		"insert_deduplicate":         1,
+		"insert_deduplication_token": "0did:erc721:1:0x1:1!2024-06-01T12:00:00Z!status!src!id",
+	}, InsertDeduplicationSettings("0did:erc721:1:0x1:1!2024-06-01T12:00:00Z!status!src!id"))
+}
+
+func TestBatchDeduplicationToken(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"b", "a", "c"}
+	token := BatchDeduplicationToken(keys)
+	assert.Len(t, token, 64)
+	assert.Equal(t, token, BatchDeduplicationToken([]string{"c", "b", "a"}), "order does not matter")
+	assert.Equal(t, []string{"b", "a", "c"}, keys, "keys are not reordered in place")
+	assert.NotEqual(t, token, BatchDeduplicationToken([]string{"a", "b"}))
+	assert.NotEqual(t, BatchDeduplicationToken([]string{"ab", "c"}), BatchDeduplicationToken([]string{"a", "bc"}), "keys are delimited")
+}
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cloud_event_tmp AS cloud_event ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (subject, event_time, event_type, source, id) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_tmp SELECT * FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_tmp TO cloud_event;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cloud_event_tmp AS cloud_event ENGINE = ReplacingMergeTree()
+ORDER BY (subject, event_time, event_type, source, id) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_tmp SELECT * FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_tmp TO cloud_event;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Point lookups by id or index_key cannot use the primary key, so give them bloom filters.
+ALTER TABLE cloud_event ADD INDEX IF NOT EXISTS idx_id id TYPE bloom_filter GRANULARITY 1;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event ADD INDEX IF NOT EXISTS idx_index_key index_key TYPE bloom_filter GRANULARITY 1;
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- Only a handful of sources write for any one subject, so a set index stays small.
+ALTER TABLE cloud_event ADD INDEX IF NOT EXISTS idx_source source TYPE set(0) GRANULARITY 1;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event MATERIALIZE INDEX idx_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event MATERIALIZE INDEX idx_index_key;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event MATERIALIZE INDEX idx_source;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cloud_event DROP INDEX IF EXISTS idx_source;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event DROP INDEX IF EXISTS idx_index_key;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event DROP INDEX IF EXISTS idx_id;
+-- +goose StatementEnd
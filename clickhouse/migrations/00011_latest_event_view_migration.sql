@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Holds only the newest row per (subject, event_type, data_version); ReplacingMergeTree keeps the max event_time.
+-- Not partitioned so that replacement works across months.
+CREATE TABLE IF NOT EXISTS cloud_event_latest AS cloud_event ENGINE = ReplacingMergeTree(event_time)
+ORDER BY (subject, event_type, data_version) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS cloud_event_latest_mv TO cloud_event_latest AS
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id
+FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Backfill after the view exists; rows inserted in between land twice and are collapsed by the engine.
+INSERT INTO cloud_event_latest
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id
+FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS cloud_event_latest_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event_latest;
+-- +goose StatementEnd
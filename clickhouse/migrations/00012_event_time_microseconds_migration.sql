@@ -0,0 +1,129 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The view must not write into the tables while they are rebuilt.
+DROP VIEW IF EXISTS cloud_event_latest_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- event_time is part of the sorting and partition keys, so its type cannot be altered in place.
+CREATE TABLE IF NOT EXISTS cloud_event_tmp (
+    subject String COMMENT 'identifying the subject of the event within the context of the event producer',
+    event_time DateTime64(6, 'UTC') COMMENT 'Time at which the event occurred.',
+    event_type String COMMENT 'event type for this object',
+    id String COMMENT 'Identifier for the event.',
+    source String COMMENT 'Entity that is responsible for providing this cloud event',
+    producer String COMMENT 'specific instance, process or device that creates the data structure describing the cloud event.',
+    data_content_type String COMMENT 'Type of data of this object.',
+    data_version String COMMENT 'Version of the data stored for this cloud event.',
+    extras String COMMENT 'Extra metadata for the cloud event',
+    index_key String COMMENT 'Key of the backing object for this cloud event',
+    data_index_key String COMMENT 'Key of the object holding the data payload. Empty for small events that are entirely stored in the object referenced by index_key.',
+    voids_id String DEFAULT '' COMMENT 'For dimo.tombstone events, the id of the event being tombstoned. Empty for all other event types.',
+    INDEX idx_event_type event_type TYPE set(0) GRANULARITY 1,
+    INDEX idx_id id TYPE bloom_filter GRANULARITY 1,
+    INDEX idx_index_key index_key TYPE bloom_filter GRANULARITY 1,
+    INDEX idx_source source TYPE set(0) GRANULARITY 1
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (subject, event_time, event_type, source, id) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_tmp SELECT * FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_tmp TO cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cloud_event_latest_tmp AS cloud_event ENGINE = ReplacingMergeTree(event_time)
+ORDER BY (subject, event_type, data_version) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_latest_tmp SELECT * FROM cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_latest_tmp TO cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS cloud_event_latest_mv TO cloud_event_latest AS
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id
+FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The view must not write into the tables while they are rebuilt.
+DROP VIEW IF EXISTS cloud_event_latest_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Sub-millisecond precision is truncated.
+CREATE TABLE IF NOT EXISTS cloud_event_tmp (
+    subject String COMMENT 'identifying the subject of the event within the context of the event producer',
+    event_time DateTime64(3, 'UTC') COMMENT 'Time at which the event occurred.',
+    event_type String COMMENT 'event type for this object',
+    id String COMMENT 'Identifier for the event.',
+    source String COMMENT 'Entity that is responsible for providing this cloud event',
+    producer String COMMENT 'specific instance, process or device that creates the data structure describing the cloud event.',
+    data_content_type String COMMENT 'Type of data of this object.',
+    data_version String COMMENT 'Version of the data stored for this cloud event.',
+    extras String COMMENT 'Extra metadata for the cloud event',
+    index_key String COMMENT 'Key of the backing object for this cloud event',
+    data_index_key String COMMENT 'Key of the object holding the data payload. Empty for small events that are entirely stored in the object referenced by index_key.',
+    voids_id String DEFAULT '' COMMENT 'For dimo.tombstone events, the id of the event being tombstoned. Empty for all other event types.',
+    INDEX idx_event_type event_type TYPE set(0) GRANULARITY 1,
+    INDEX idx_id id TYPE bloom_filter GRANULARITY 1,
+    INDEX idx_index_key index_key TYPE bloom_filter GRANULARITY 1,
+    INDEX idx_source source TYPE set(0) GRANULARITY 1
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (subject, event_time, event_type, source, id) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_tmp SELECT * FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_tmp TO cloud_event;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cloud_event_latest_tmp AS cloud_event ENGINE = ReplacingMergeTree(event_time)
+ORDER BY (subject, event_type, data_version) SETTINGS index_granularity = 8192;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO cloud_event_latest_tmp SELECT * FROM cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+RENAME TABLE cloud_event_latest_tmp TO cloud_event_latest;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS cloud_event_latest_mv TO cloud_event_latest AS
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id
+FROM cloud_event;
+-- +goose StatementEnd
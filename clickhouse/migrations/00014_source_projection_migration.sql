@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ReplacingMergeTree refuses projections unless told what to do with them when merges drop rows.
+ALTER TABLE cloud_event MODIFY SETTING deduplicate_merge_projection_mode = 'rebuild';
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- Serves queries filtering on source and event_time across all subjects.
+ALTER TABLE cloud_event ADD PROJECTION IF NOT EXISTS proj_source_time (SELECT * ORDER BY source, event_time);
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event MATERIALIZE PROJECTION proj_source_time;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cloud_event DROP PROJECTION IF EXISTS proj_source_time;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event RESET SETTING deduplicate_merge_projection_mode;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP VIEW IF EXISTS cloud_event_latest_mv;
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- Rows written before this migration keep their signature in extras and have NULL here.
+ALTER TABLE cloud_event ADD COLUMN IF NOT EXISTS signature Nullable(String) COMMENT 'Signature of the event data. NULL for unsigned events and for rows that keep it in extras.' AFTER voids_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event_latest ADD COLUMN IF NOT EXISTS signature Nullable(String) COMMENT 'Signature of the event data. NULL for unsigned events and for rows that keep it in extras.' AFTER voids_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS cloud_event_latest_mv TO cloud_event_latest AS
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id, signature
+FROM cloud_event;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS cloud_event_latest_mv;
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- The projection selects every column, so it has to go before the column can be dropped.
+ALTER TABLE cloud_event DROP PROJECTION IF EXISTS proj_source_time;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event DROP COLUMN IF EXISTS signature;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event ADD PROJECTION IF NOT EXISTS proj_source_time (SELECT * ORDER BY source, event_time);
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event MATERIALIZE PROJECTION proj_source_time;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE cloud_event_latest DROP COLUMN IF EXISTS signature;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS cloud_event_latest_mv TO cloud_event_latest AS
+SELECT subject, event_time, event_type, id, source, producer, data_content_type, data_version, extras, index_key, data_index_key, voids_id
+FROM cloud_event;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Non-replicated MergeTree tables only deduplicate inserts, by block hash or by
+-- insert_deduplication_token, when they remember recent blocks.
+ALTER TABLE cloud_event MODIFY SETTING non_replicated_deduplication_window = 10000;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cloud_event RESET SETTING non_replicated_deduplication_window;
+-- +goose StatementEnd
@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// destructiveStmt matches statements that remove stored rows, columns, views, indexes or
+// projections, or that change a column's type, which can truncate stored values.
+var destructiveStmt = regexp.MustCompile(`(?i)\b(DROP\s+(TABLE|COLUMN|DATABASE|VIEW|INDEX|PROJECTION)|MODIFY\s+COLUMN|TRUNCATE|DELETE\s+FROM|ALTER\s+TABLE\s+\S+\s+DELETE)\b`)
+
+// DownOptions controls MigrateDownTo.
+type DownOptions struct {
+	// AllowDestructive permits rolling back migrations that drop tables, columns, views, indexes
+	// or projections, or that change column types.
+	// Registered Go migrations are always treated as destructive because their statements are unknown.
+	AllowDestructive bool
+	// Report, if set, receives the plan before it runs and the row counts of every table before and after.
+	Report io.Writer
+}
+
+// MigrateDownTo rolls db back to version, which must be at least 1.
+// The plan is checked before anything runs; if it contains destructive statements
+// and opts.AllowDestructive is not set, an error listing them is returned and db is untouched.
+// Rolling back to a version at or above the current one does nothing.
+func MigrateDownTo(ctx context.Context, db *sql.DB, version int64, opts DownOptions) error {
+	if version < 1 {
+		return fmt.Errorf("cannot migrate down to version %d, the lowest version is 1", version)
+	}
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+	plan, err := planTo(applied, version)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		return nil
+	}
+	if plan[0].Direction != DirectionDown {
+		return fmt.Errorf("cannot migrate down to version %d, it is above the current version", version)
+	}
+	if destructive := destructiveStatements(plan); len(destructive) > 0 && !opts.AllowDestructive {
+		var errs []error
+		for _, stmt := range destructive {
+			errs = append(errs, fmt.Errorf("%s: %s", stmt.Name, stmt.SQL))
+		}
+		return fmt.Errorf("down migration to version %d is destructive, set AllowDestructive to run it: %w", version, errors.Join(errs...))
+	}
+
+	report := opts.Report
+	if report == nil {
+		report = io.Discard
+	}
+	if err := printPlan(report, plan); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	before, err := tableRowCounts(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := RunGoose(ctx, []string{"down-to", strconv.FormatInt(version, 10)}, db); err != nil {
+		return err
+	}
+	after, err := tableRowCounts(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := printRowCounts(report, before, after); err != nil {
+		return fmt.Errorf("failed to write row counts: %w", err)
+	}
+	return nil
+}
+
+// destructiveStatements returns the statements in plan that may remove data.
+func destructiveStatements(plan []PlannedStatement) []PlannedStatement {
+	var destructive []PlannedStatement
+	for _, stmt := range plan {
+		if stmt.SQL == registeredPlanSQL || destructiveStmt.MatchString(stmt.SQL) {
+			destructive = append(destructive, stmt)
+		}
+	}
+	return destructive
+}
+
+// tableRowCounts returns the row count of every table in the current database except the goose version table.
+// Views are skipped since they hold no rows of their own.
+func tableRowCounts(ctx context.Context, db *sql.DB) (map[string]uint64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, total_rows FROM system.tables WHERE database = currentDatabase() AND name != 'goose_db_version' AND total_rows IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count table rows: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // the error from rows.Err is returned instead
+	counts := map[string]uint64{}
+	for rows.Next() {
+		var name string
+		var count uint64
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan table row count: %w", err)
+		}
+		counts[name] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count table rows: %w", err)
+	}
+	return counts, nil
+}
+
+// printRowCounts writes the row count of every table seen before or after a migration to w.
+// Tables that did not exist on one side are reported as "-".
+func printRowCounts(w io.Writer, before, after map[string]uint64) error {
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	format := func(counts map[string]uint64, name string) string {
+		if count, ok := counts[name]; ok {
+			return strconv.FormatUint(count, 10)
+		}
+		return "-"
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "-- %s rows: %s -> %s\n", name, format(before, name), format(after, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestructiveStatements(t *testing.T) {
+	t.Parallel()
+
+	plan := []PlannedStatement{
+		{Name: "a", SQL: "ALTER TABLE cloud_event DROP INDEX IF EXISTS idx_id;"},
+		{Name: "b", SQL: "DROP VIEW IF EXISTS cloud_event_latest_mv;"},
+		{Name: "c", SQL: "ALTER TABLE cloud_event DROP COLUMN IF EXISTS voids_id;"},
+		{Name: "d", SQL: "drop table cloud_event_latest;"},
+		{Name: "e", SQL: "TRUNCATE TABLE cloud_event;"},
+		{Name: "f", SQL: "ALTER TABLE cloud_event DELETE WHERE id = '1';"},
+		{Name: "g", SQL: registeredPlanSQL},
+		{Name: "h", SQL: "SELECT 'dropped_tables';"},
+		{Name: "i", SQL: "ALTER TABLE cloud_event MODIFY COLUMN event_time DateTime64(3, 'UTC');"},
+		{Name: "j", SQL: "ALTER TABLE cloud_event DROP PROJECTION IF EXISTS proj_source_time;"},
+		{Name: "k", SQL: "ALTER TABLE cloud_event MODIFY SETTING non_replicated_deduplication_window = 0;"},
+	}
+	var names []string
+	for _, stmt := range destructiveStatements(plan) {
+		names = append(names, stmt.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "i", "j"}, names)
+}
+
+func TestMigrateDownTo_BelowFirstVersion(t *testing.T) {
+	t.Parallel()
+
+	// The version is checked before the database is touched.
+	err := MigrateDownTo(context.Background(), nil, 0, DownOptions{AllowDestructive: true})
+	require.Error(t, err)
+}
+
+func TestPrintRowCounts(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := printRowCounts(&buf,
+		map[string]uint64{"a": 2, "b": 5},
+		map[string]uint64{"a": 2, "c": 0},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "-- a rows: 2 -> 2\n-- b rows: 5 -> -\n-- c rows: - -> 0\n", buf.String())
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"os"
+	"slices"
 
 	"github.com/DIMO-Network/clickhouse-infra/pkg/migrate"
 )
@@ -13,9 +15,21 @@ import (
 //go:embed *.sql
 var BaseFS embed.FS
 
+// dryRunFlag makes RunGoose print the planned SQL instead of executing it.
+const dryRunFlag = "--dry-run"
+
 // RunGoose runs the goose command with the provided arguments.
 // args should be the command and the arguments to pass to goose.
 // eg RunGoose(ctx, []string{"up", "-v"}, db).
+// Adding "--dry-run" to an up, up-to, down or down-to command prints the SQL
+// it would execute to stdout instead of running it.
+// Migrations added with RegisterMigration are applied after the embedded ones.
 func RunGoose(ctx context.Context, gooseArgs []string, db *sql.DB) error {
+	if i := slices.Index(gooseArgs, dryRunFlag); i >= 0 {
+		return dryRun(ctx, slices.Delete(slices.Clone(gooseArgs), i, i+1), db, os.Stdout)
+	}
+	if len(registeredMigrations()) > 0 {
+		return runGooseWithRegistered(ctx, gooseArgs, db)
+	}
 	return migrate.RunGoose(ctx, gooseArgs, BaseFS, db)
 }
@@ -2,6 +2,8 @@ package migrations_test
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -39,8 +41,10 @@ func TestMigration(t *testing.T) {
 		Time:        time.Now(),
 		Type:        cloudevent.TypeStatus,
 		Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+		ID:          "migration-test",
 		DataVersion: "Stat/2.0.0",
 		Producer:    cloudevent.ERC721DID{ChainID: 3, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+		Signature:   "0xdeadbeef",
 	}
 	err = insertIndex(conn, hdr)
 	require.NoError(t, err, "Failed to insert new index")
@@ -61,6 +65,7 @@ func TestMigration(t *testing.T) {
 		localch.IndexKeyColumn,
 		localch.DataIndexKeyColumn,
 		localch.VoidsIDColumn,
+		localch.SignatureColumn,
 	}
 	assert.ElementsMatch(t, expectedCols, cols, "Columns do not match")
 
@@ -75,6 +80,30 @@ func TestMigration(t *testing.T) {
 		localch.IDColumn,
 	}
 	assert.ElementsMatch(t, expectedOrderByCols, orderByCols, "Order by columns do not match")
+
+	// Check the data skipping indexes
+	skipIndexes, err := getSkipIndexes(ctx, conn, localch.TableName)
+	require.NoError(t, err, "Failed to get skip indexes")
+	assert.ElementsMatch(t, []string{"idx_event_type", "idx_id", "idx_index_key", "idx_source"}, skipIndexes, "Skip indexes do not match")
+
+	// Point lookups served by the skip indexes must still return the row.
+	var indexKey string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IndexKeyColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", hdr.ID).Scan(&indexKey)
+	require.NoError(t, err, "Failed to query by id")
+	assert.Equal(t, localch.CloudEventToObjectKey(&hdr), indexKey)
+	var id string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.TableName+" WHERE "+localch.IndexKeyColumn+" = ?;", indexKey).Scan(&id)
+	require.NoError(t, err, "Failed to query by index key")
+	assert.Equal(t, hdr.ID, id)
+
+	// The signature is stored in its own column, not in extras.
+	var signature *string
+	var extras string
+	err = conn.QueryRow(ctx, "SELECT "+localch.SignatureColumn+", "+localch.ExtrasColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", hdr.ID).Scan(&signature, &extras)
+	require.NoError(t, err, "Failed to query signature")
+	require.NotNil(t, signature)
+	assert.Equal(t, hdr.Signature, *signature)
+	assert.Equal(t, "{}", extras)
 	// Close the DB connection
 	err = db.Close()
 	assert.NoError(t, err, "Failed to close DB connection")
@@ -82,6 +111,751 @@ func TestMigration(t *testing.T) {
 	assert.NoError(t, err, "Failed to close clickhouse connection")
 }
 
+func TestMigration_PartitionPreservesRows(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	// Bring the schema up to the last unpartitioned version and write a row.
+	err = migrations.RunGoose(ctx, []string{"up-to", "8"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	hdr := cloudevent.CloudEventHeader{
+		Subject:     cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+		Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Type:        cloudevent.TypeStatus,
+		Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+		ID:          "pre-partition",
+		DataVersion: "Stat/2.0.0",
+	}
+	err = insertLegacyIndex(conn, hdr)
+	require.NoError(t, err, "Failed to insert new index")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	var partitionKey string
+	err = conn.QueryRow(ctx, "SELECT partition_key FROM system.tables WHERE name = ?;", localch.TableName).Scan(&partitionKey)
+	require.NoError(t, err, "Failed to get partition key")
+	assert.Equal(t, "toYYYYMM(event_time)", partitionKey)
+
+	orderByCols, err := getOrderByCols(ctx, conn, localch.TableName)
+	require.NoError(t, err, "Failed to get order by columns")
+	assert.Equal(t, []string{
+		localch.SubjectColumn,
+		localch.TimestampColumn,
+		localch.TypeColumn,
+		localch.SourceColumn,
+		localch.IDColumn,
+	}, orderByCols, "Order by columns do not match")
+
+	var indexKey string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IndexKeyColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", hdr.ID).Scan(&indexKey)
+	require.NoError(t, err, "Row written before the migration is not queryable")
+	assert.Equal(t, localch.CloudEventToObjectKey(&hdr), indexKey)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestMigration_LatestView(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	subject := cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String()
+	source := common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	newHdr := func(id string, offset time.Duration) cloudevent.CloudEventHeader {
+		return cloudevent.CloudEventHeader{
+			Subject:     subject,
+			Time:        start.Add(offset),
+			Type:        cloudevent.TypeStatus,
+			Source:      source,
+			ID:          id,
+			DataVersion: "Stat/2.0.0",
+		}
+	}
+
+	// Rows written before the view exists are picked up by the backfill.
+	err = migrations.RunGoose(ctx, []string{"up-to", "10"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	require.NoError(t, insertLegacyIndex(conn, newHdr("backfilled", 0)))
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	latestID := func() (string, string) {
+		var fast, slow string
+		err := conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.LatestTableName+" FINAL WHERE "+localch.SubjectColumn+" = ?;", subject).Scan(&fast)
+		require.NoError(t, err, "Failed to query latest view")
+		err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.TableName+" WHERE "+localch.SubjectColumn+" = ? ORDER BY "+localch.TimestampColumn+" DESC LIMIT 1;", subject).Scan(&slow)
+		require.NoError(t, err, "Failed to query base table")
+		return fast, slow
+	}
+
+	fast, slow := latestID()
+	assert.Equal(t, "backfilled", fast)
+	assert.Equal(t, slow, fast)
+
+	// Newer rows replace older ones; older rows arriving late do not.
+	require.NoError(t, insertIndex(conn, newHdr("newest", 2*time.Hour)))
+	require.NoError(t, insertIndex(conn, newHdr("late", time.Hour)))
+
+	fast, slow = latestID()
+	assert.Equal(t, "newest", fast)
+	assert.Equal(t, slow, fast)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestMigration_MicrosecondEventTime(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	subject := cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	newHdr := func(id string, at time.Time) cloudevent.CloudEventHeader {
+		return cloudevent.CloudEventHeader{
+			Subject:     subject,
+			Time:        at,
+			Type:        cloudevent.TypeStatus,
+			Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+			ID:          id,
+			DataVersion: "Stat/2.0.0",
+		}
+	}
+	eventTime := func(id string) time.Time {
+		var eventTime time.Time
+		err := conn.QueryRow(ctx, "SELECT "+localch.TimestampColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", id).Scan(&eventTime)
+		require.NoError(t, err, "Failed to query event %s", id)
+		return eventTime.UTC()
+	}
+
+	// Millisecond rows written before the migration must survive it unchanged.
+	err = migrations.RunGoose(ctx, []string{"up-to", "11"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	legacy := start.Add(123 * time.Millisecond)
+	require.NoError(t, insertLegacyIndex(conn, newHdr("legacy", legacy)))
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	assert.Equal(t, legacy, eventTime("legacy"))
+
+	var eventTimeType string
+	err = conn.QueryRow(ctx, "SELECT type FROM system.columns WHERE table = ? AND name = ?;", localch.TableName, localch.TimestampColumn).Scan(&eventTimeType)
+	require.NoError(t, err, "Failed to get event_time type")
+	assert.Equal(t, "DateTime64(6, 'UTC')", eventTimeType)
+
+	// Events 300µs apart keep their order and their exact time.
+	first := start.Add(time.Second + 100*time.Microsecond)
+	second := first.Add(300 * time.Microsecond)
+	require.NoError(t, insertIndex(conn, newHdr("second", second)))
+	require.NoError(t, insertIndex(conn, newHdr("first", first)))
+	assert.Equal(t, first, eventTime("first"))
+	assert.Equal(t, second, eventTime("second"))
+
+	var latest string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.LatestTableName+" FINAL WHERE "+localch.SubjectColumn+" = ?;", subject).Scan(&latest)
+	require.NoError(t, err, "Failed to query latest view")
+	assert.Equal(t, "second", latest)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestMigration_LowCardinality(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	newHdr := func(id string) cloudevent.CloudEventHeader {
+		return cloudevent.CloudEventHeader{
+			Subject:         cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+			Time:            time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			Type:            cloudevent.TypeStatus,
+			Source:          common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+			ID:              id,
+			DataContentType: "application/json",
+			DataVersion:     "Stat/2.0.0",
+		}
+	}
+	assertRow := func(hdr cloudevent.CloudEventHeader) {
+		t.Helper()
+		var eventType, source, dataContentType, dataVersion string
+		err := conn.QueryRow(ctx, "SELECT "+localch.TypeColumn+", "+localch.SourceColumn+", "+localch.DataContentTypeColumn+", "+localch.DataVersionColumn+
+			" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ? AND "+localch.TypeColumn+" = ?;", hdr.ID, hdr.Type).
+			Scan(&eventType, &source, &dataContentType, &dataVersion)
+		require.NoError(t, err, "Failed to query event %s", hdr.ID)
+		assert.Equal(t, hdr.Type, eventType)
+		assert.Equal(t, hdr.Source, source)
+		assert.Equal(t, hdr.DataContentType, dataContentType)
+		assert.Equal(t, hdr.DataVersion, dataVersion)
+	}
+
+	err = migrations.RunGoose(ctx, []string{"up-to", "12"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	before := newHdr("before")
+	require.NoError(t, insertLegacyIndex(conn, before))
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	for _, col := range []string{localch.TypeColumn, localch.SourceColumn, localch.DataContentTypeColumn, localch.DataVersionColumn} {
+		var colType string
+		err = conn.QueryRow(ctx, "SELECT type FROM system.columns WHERE table = ? AND name = ?;", localch.TableName, col).Scan(&colType)
+		require.NoError(t, err, "Failed to get %s type", col)
+		assert.Equal(t, "LowCardinality(String)", colType, "Unexpected type for %s", col)
+	}
+
+	after := newHdr("after")
+	require.NoError(t, insertIndex(conn, after))
+	assertRow(before)
+	assertRow(after)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestMigration_SourceProjection(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	// Rows written before the projection exists are covered by MATERIALIZE PROJECTION.
+	err = migrations.RunGoose(ctx, []string{"up-to", "13"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	oracle := common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String()
+	other := common.HexToAddress("0xa57d6d57fca59d0517038c968a1b831b071fa679").String()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	insert := func(tokenID int64, source string, offset time.Duration) string {
+		hdr := cloudevent.CloudEventHeader{
+			Subject:     cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(tokenID)}.String(),
+			Time:        start.Add(offset),
+			Type:        cloudevent.TypeStatus,
+			Source:      source,
+			ID:          fmt.Sprintf("%d-%s-%s", tokenID, source, offset),
+			DataVersion: "Stat/2.0.0",
+		}
+		require.NoError(t, insertLegacyIndex(conn, hdr))
+		return hdr.ID
+	}
+
+	var want []string
+	for tokenID := int64(1); tokenID <= 3; tokenID++ {
+		insert(tokenID, oracle, -time.Minute)
+		want = append(want, insert(tokenID, oracle, time.Duration(tokenID)*time.Minute))
+		insert(tokenID, other, time.Duration(tokenID)*time.Minute)
+		insert(tokenID, oracle, 2*time.Hour)
+	}
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	var createQuery string
+	err = conn.QueryRow(ctx, "SELECT create_table_query FROM system.tables WHERE name = ?;", localch.TableName).Scan(&createQuery)
+	require.NoError(t, err, "Failed to get table definition")
+	assert.Contains(t, createQuery, "PROJECTION proj_source_time")
+
+	// One more subject after the migration so both materialized and newly written parts are read.
+	want = append(want, insert(4, oracle, 4*time.Minute))
+
+	rows, err := conn.Query(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.TableName+
+		" WHERE "+localch.SourceColumn+" = ? AND "+localch.TimestampColumn+" >= ? AND "+localch.TimestampColumn+" < ? ORDER BY "+localch.TimestampColumn+";",
+		oracle, start, start.Add(time.Hour))
+	require.NoError(t, err, "Failed to query by source")
+	var got []string
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		got = append(got, id)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, want, got)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestMigration_SignatureColumn(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	newHdr := func(id, signature string) cloudevent.CloudEventHeader {
+		return cloudevent.CloudEventHeader{
+			Subject:     cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+			Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			Type:        cloudevent.TypeStatus,
+			Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+			ID:          id,
+			DataVersion: "Stat/2.0.0",
+			Signature:   signature,
+		}
+	}
+	// restoredSignature reads a row back the way a reader would: the column wins, extras is the fallback.
+	restoredSignature := func(id string) string {
+		t.Helper()
+		var signature *string
+		var extras string
+		err := conn.QueryRow(ctx, "SELECT "+localch.SignatureColumn+", "+localch.ExtrasColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", id).Scan(&signature, &extras)
+		require.NoError(t, err, "Failed to query event %s", id)
+		hdr := cloudevent.CloudEventHeader{}
+		require.NoError(t, json.Unmarshal([]byte(extras), &hdr.Extras))
+		cloudevent.RestoreNonColumnFields(&hdr)
+		if signature != nil {
+			hdr.Signature = *signature
+		}
+		return hdr.Signature
+	}
+
+	err = migrations.RunGoose(ctx, []string{"up-to", "14"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	require.NoError(t, insertLegacyIndex(conn, newHdr("legacy", "0xlegacy")))
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	require.NoError(t, insertIndex(conn, newHdr("signed", "0xsigned")))
+	require.NoError(t, insertIndex(conn, newHdr("unsigned", "")))
+
+	assert.Equal(t, "0xlegacy", restoredSignature("legacy"))
+	assert.Equal(t, "0xsigned", restoredSignature("signed"))
+	assert.Empty(t, restoredSignature("unsigned"))
+
+	var signedID string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.TableName+" WHERE "+localch.SignatureColumn+" IS NOT NULL;").Scan(&signedID)
+	require.NoError(t, err, "Failed to query signed events")
+	assert.Equal(t, "signed", signedID)
+
+	var latestSignature *string
+	err = conn.QueryRow(ctx, "SELECT "+localch.SignatureColumn+" FROM "+localch.LatestTableName+" FINAL WHERE "+localch.IDColumn+" = ?;", "signed").Scan(&latestSignature)
+	require.NoError(t, err, "Failed to query latest view")
+	require.NotNil(t, latestSignature)
+	assert.Equal(t, "0xsigned", *latestSignature)
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestInsertDeduplication(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	hdr := cloudevent.CloudEventHeader{
+		Subject:     cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+		Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Type:        cloudevent.TypeStatus,
+		Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+		ID:          "retried",
+		DataVersion: "Stat/2.0.0",
+	}
+	indexKey := localch.CloudEventToObjectKey(&hdr)
+	for i := range 3 {
+		// Vary the extras so block hashing alone would not catch the retry.
+		hdr.Extras = map[string]any{"attempt": i}
+		insertCtx := localch.WithInsertDeduplication(ctx, indexKey)
+		err := conn.Exec(insertCtx, localch.InsertStmt, localch.CloudEventToSliceWithKey(&hdr, indexKey)...)
+		require.NoError(t, err, "Failed to insert attempt %d", i)
+	}
+
+	var count uint64
+	err = conn.QueryRow(ctx, "SELECT count() FROM "+localch.TableName+" WHERE "+localch.IndexKeyColumn+" = ?;", indexKey).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count, "Retried inserts must be dropped without FINAL")
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestBatchInsertDeduplication(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	var headers []cloudevent.CloudEventHeader
+	var indexKeys []string
+	for i := range 3 {
+		hdr := cloudevent.CloudEventHeader{
+			Subject: cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+			Time:    time.Date(2024, 6, 1, 12, 0, i, 0, time.UTC),
+			Type:    cloudevent.TypeStatus,
+			Source:  common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+			ID:      fmt.Sprintf("batch-%d", i),
+		}
+		headers = append(headers, hdr)
+		indexKeys = append(indexKeys, localch.CloudEventToObjectKey(&hdr))
+	}
+	for attempt := range 2 {
+		insertCtx := localch.WithBatchInsertDeduplication(ctx, indexKeys)
+		batch, err := conn.PrepareBatch(insertCtx, localch.InsertStmt)
+		require.NoError(t, err)
+		for i := range headers {
+			require.NoError(t, batch.Append(localch.CloudEventToSliceWithKey(&headers[i], indexKeys[i])...))
+		}
+		require.NoError(t, batch.Send(), "Failed to send attempt %d", attempt)
+	}
+
+	var count uint64
+	err = conn.QueryRow(ctx, "SELECT count() FROM "+localch.TableName).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(headers)), count, "The retried batch must be dropped without FINAL")
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	twoYearsAgo := time.Now().UTC().AddDate(-2, 0, 0)
+	for _, eventType := range []string{cloudevent.TypeFingerprint, cloudevent.TypeAttestation, cloudevent.TypeStatus} {
+		hdr := cloudevent.CloudEventHeader{
+			Subject: cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+			Time:    twoYearsAgo,
+			Type:    eventType,
+			Source:  common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+			ID:      eventType,
+		}
+		require.NoError(t, insertIndex(conn, hdr))
+	}
+
+	const day = 24 * time.Hour
+	err = localch.ApplyRetentionPolicy(ctx, db, map[string]time.Duration{
+		cloudevent.TypeFingerprint:   395 * day,
+		cloudevent.TypeAttestation:   7 * 365 * day,
+		localch.DefaultRetentionType: 365 * day,
+	})
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "ALTER TABLE "+localch.TableName+" MATERIALIZE TTL SETTINGS mutations_sync = 2")
+	require.NoError(t, err)
+
+	rows, err := conn.Query(ctx, "SELECT "+localch.TypeColumn+" FROM "+localch.TableName+" FINAL")
+	require.NoError(t, err)
+	var remaining []string
+	for rows.Next() {
+		var eventType string
+		require.NoError(t, rows.Scan(&eventType))
+		remaining = append(remaining, eventType)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{cloudevent.TypeAttestation}, remaining, "Only the attestation is within its retention")
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestVerifySchema(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	_, err = localch.VerifySchema(ctx, db)
+	require.Error(t, err, "Verifying a missing table must fail")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	report, err := localch.VerifySchema(ctx, db)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "Fresh schema has drifted: %+v", report.Diffs)
+
+	_, err = db.ExecContext(ctx, "ALTER TABLE "+localch.TableName+" ADD COLUMN hotfix String")
+	require.NoError(t, err)
+
+	report, err = localch.VerifySchema(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []localch.SchemaDiff{{Kind: localch.SchemaDiffColumn, Name: "hotfix", Actual: "String"}}, report.Diffs)
+
+	require.NoError(t, db.Close())
+}
+
+func TestMigrateDownTo(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	upSchema := getSchema(ctx, t, db)
+	hdr := cloudevent.CloudEventHeader{
+		Subject:     cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+		Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Type:        cloudevent.TypeStatus,
+		Source:      common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+		ID:          "down-to",
+		DataVersion: "Stat/2.0.0",
+	}
+	require.NoError(t, insertIndex(conn, hdr))
+
+	// Rolling back the latest-event table drops it, so it must be allowed explicitly.
+	err = migrations.MigrateDownTo(ctx, db, 10, migrations.DownOptions{})
+	require.Error(t, err)
+	current, err := migrations.CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, migrations.LatestVersion(), current, "A refused rollback must not change the schema")
+
+	var report strings.Builder
+	err = migrations.MigrateDownTo(ctx, db, 10, migrations.DownOptions{AllowDestructive: true, Report: &report})
+	require.NoError(t, err, "Failed to migrate down")
+	assert.Contains(t, report.String(), "DROP TABLE IF EXISTS cloud_event_latest;")
+	assert.Contains(t, report.String(), "-- cloud_event rows: 1 -> 1")
+	assert.Contains(t, report.String(), "-- cloud_event_latest rows: 1 -> -")
+
+	current, err = migrations.CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), current)
+	schema := getSchema(ctx, t, db)
+	assert.NotContains(t, schema, localch.LatestTableName)
+	assert.NotContains(t, schema, "cloud_event_latest_mv")
+
+	var id string
+	err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.TableName+" WHERE "+localch.IDColumn+" = ?;", hdr.ID).Scan(&id)
+	require.NoError(t, err, "Row must survive the rollback")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	assert.Equal(t, upSchema, getSchema(ctx, t, db))
+	err = conn.QueryRow(ctx, "SELECT "+localch.IDColumn+" FROM "+localch.LatestTableName+" FINAL WHERE "+localch.SubjectColumn+" = ?;", hdr.Subject).Scan(&id)
+	require.NoError(t, err, "Latest table must be backfilled after migrating up again")
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
+func TestLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, int64(16), migrations.LatestVersion())
+}
+
+func TestMigrationStatus(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	upToDate, err := migrations.IsUpToDate(ctx, db)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	pending, err := migrations.Pending(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, pending, int(migrations.LatestVersion()))
+	assert.Equal(t, migrations.MigrationInfo{Version: 1, Name: "00001_init_migration.sql"}, pending[0])
+	assert.Equal(t, migrations.LatestVersion(), pending[len(pending)-1].Version)
+
+	current, err := migrations.CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), current)
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	upToDate, err = migrations.IsUpToDate(ctx, db)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	pending, err = migrations.Pending(ctx, db)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	current, err = migrations.CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, migrations.LatestVersion(), current)
+
+	require.NoError(t, db.Close())
+}
+
+// TestPlanMigrations executes the planned statements by hand and checks the
+// resulting schema matches a real goose run.
+func TestPlanMigrations(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	plan, err := migrations.PlanMigrations(ctx, db, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, plan)
+	assert.Equal(t, int64(1), plan[0].Version)
+	assert.Equal(t, migrations.LatestVersion(), plan[len(plan)-1].Version)
+
+	for _, stmt := range plan {
+		_, err := db.ExecContext(ctx, stmt.SQL)
+		require.NoError(t, err, "Failed to execute planned statement from %s", stmt.Name)
+	}
+	planned := getSchema(ctx, t, db)
+
+	// Drop everything the plan created and let goose build it for real.
+	for name := range planned {
+		if strings.HasSuffix(name, "_mv") {
+			_, err := db.ExecContext(ctx, "DROP VIEW "+name)
+			require.NoError(t, err)
+		}
+	}
+	for name := range planned {
+		if !strings.HasSuffix(name, "_mv") {
+			_, err := db.ExecContext(ctx, "DROP TABLE "+name)
+			require.NoError(t, err)
+		}
+	}
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+	assert.Equal(t, planned, getSchema(ctx, t, db))
+
+	plan, err = migrations.PlanMigrations(ctx, db, "")
+	require.NoError(t, err)
+	assert.Empty(t, plan, "Nothing should be pending after up")
+
+	require.NoError(t, db.Close())
+}
+
+// TestMigration_CustomDatabase guards against migrations referencing a
+// database by name; every statement must resolve against the connection's
+// current database.
+func TestMigration_CustomDatabase(t *testing.T) {
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{Database: "tenant_events"})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	conn, err := chcontainer.GetClickHouseAsConn()
+	require.NoError(t, err, "Failed to get clickhouse connection")
+
+	err = migrations.RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	var database string
+	err = conn.QueryRow(ctx, "SELECT database FROM system.tables WHERE name = ?;", localch.TableName).Scan(&database)
+	require.NoError(t, err, "Failed to find table")
+	assert.Equal(t, "tenant_events", database)
+
+	hdr := cloudevent.CloudEventHeader{
+		Subject: cloudevent.ERC721DID{ChainID: 2, ContractAddress: common.HexToAddress("0xc57d6d57fca59d0517038c968a1b831b071fa679"), TokenID: big.NewInt(3)}.String(),
+		Time:    time.Now(),
+		Type:    cloudevent.TypeStatus,
+		Source:  common.HexToAddress("0xb57d6d57fca59d0517038c968a1b831b071fa679").String(),
+		ID:      "custom-db",
+	}
+	err = insertIndex(conn, hdr)
+	require.NoError(t, err, "Failed to insert new index")
+
+	require.NoError(t, db.Close())
+	require.NoError(t, conn.Close())
+}
+
 func getOrderByCols(ctx context.Context, conn clickhouse.Conn, tableName string) ([]string, error) {
 	selectStm := "SELECT sorting_key FROM system.tables WHERE name = ?;"
 	row := conn.QueryRow(ctx, selectStm, tableName)
@@ -93,6 +867,39 @@ func getOrderByCols(ctx context.Context, conn clickhouse.Conn, tableName string)
 	return strings.Split(sortingKey, ", "), nil
 }
 
+// getSchema returns the create statement of every table in the current database except the goose version table.
+func getSchema(ctx context.Context, t *testing.T, db *sql.DB) map[string]string {
+	t.Helper()
+	rows, err := db.QueryContext(ctx, "SELECT name, create_table_query FROM system.tables WHERE database = currentDatabase() AND name != 'goose_db_version'")
+	require.NoError(t, err)
+	defer rows.Close() //nolint // we are not interested in the error here
+	schema := map[string]string{}
+	for rows.Next() {
+		var name, query string
+		require.NoError(t, rows.Scan(&name, &query))
+		schema[name] = query
+	}
+	require.NoError(t, rows.Err())
+	return schema
+}
+
+func getSkipIndexes(ctx context.Context, conn clickhouse.Conn, tableName string) ([]string, error) {
+	rows, err := conn.Query(ctx, "SELECT name FROM system.data_skipping_indices WHERE table = ?;", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint // we are not interested in the error here
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 func insertIndex(conn clickhouse.Conn, hdr cloudevent.CloudEventHeader) error {
 	values := localch.CloudEventToSlice(&hdr)
 	err := conn.Exec(context.Background(), localch.InsertStmt, values...)
@@ -102,6 +909,39 @@ func insertIndex(conn clickhouse.Conn, hdr cloudevent.CloudEventHeader) error {
 	return nil
 }
 
+// insertLegacyIndex writes hdr the way rows were written before the signature column
+// existed, with the signature in extras. It also works on later schemas, where the
+// signature column is left NULL.
+func insertLegacyIndex(conn clickhouse.Conn, hdr cloudevent.CloudEventHeader) error {
+	values := localch.CloudEventToSlice(&hdr)[:12]
+	extras := []byte("{}")
+	if fields := cloudevent.AddNonColumnFieldsToExtras(&hdr); fields != nil {
+		var err error
+		if extras, err = json.Marshal(fields); err != nil {
+			return fmt.Errorf("failed to marshal extras: %w", err)
+		}
+	}
+	values[8] = string(extras)
+	stmt := "INSERT INTO " + localch.TableName + " (" + strings.Join([]string{
+		localch.SubjectColumn,
+		localch.TimestampColumn,
+		localch.TypeColumn,
+		localch.IDColumn,
+		localch.SourceColumn,
+		localch.ProducerColumn,
+		localch.DataContentTypeColumn,
+		localch.DataVersionColumn,
+		localch.ExtrasColumn,
+		localch.IndexKeyColumn,
+		localch.DataIndexKeyColumn,
+		localch.VoidsIDColumn,
+	}, ", ") + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	if err := conn.Exec(context.Background(), stmt, values...); err != nil {
+		return fmt.Errorf("failed to store index in ClickHouse: %w", err)
+	}
+	return nil
+}
+
 func GetTableCols(ctx context.Context, chConn clickhouse.Conn, tableName string) ([]string, error) {
 	selectStm := fmt.Sprintf("SELECT name FROM system.columns where table='%s'", tableName)
 	rows, err := chConn.Query(ctx, selectStm)
@@ -0,0 +1,290 @@
+package migrations
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	// DirectionUp marks a statement run when applying a migration.
+	DirectionUp = "up"
+	// DirectionDown marks a statement run when rolling a migration back.
+	DirectionDown = "down"
+)
+
+// PlannedStatement is a single SQL statement that a migration would execute.
+type PlannedStatement struct {
+	Version   int64
+	Name      string
+	Direction string
+	SQL       string
+}
+
+// PlanMigrations returns, in execution order, the SQL statements that migrating db to target would run,
+// without executing any of them. target is a migration version; an empty target means LatestVersion.
+// A target below the current version plans the down migrations needed to reach it.
+func PlanMigrations(ctx context.Context, db *sql.DB, target string) ([]PlannedStatement, error) {
+	targetVersion := LatestVersion()
+	if target != "" {
+		var err error
+		targetVersion, err = strconv.ParseInt(target, 10, 64)
+		if err != nil || targetVersion < 0 {
+			return nil, fmt.Errorf("invalid target version %q", target)
+		}
+	}
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return planTo(applied, targetVersion)
+}
+
+// appliedVersions returns the set of migration versions recorded in db.
+func appliedVersions(ctx context.Context, db *sql.DB) (map[int64]bool, error) {
+	provider, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+	applied := make(map[int64]bool, len(statuses))
+	for _, status := range statuses {
+		if status.State == goose.StateApplied {
+			applied[status.Source.Version] = true
+		}
+	}
+	return applied, nil
+}
+
+// planTo plans the statements that move a database with the given applied versions to target.
+func planTo(applied map[int64]bool, target int64) ([]PlannedStatement, error) {
+	var current int64
+	for version := range applied {
+		current = max(current, version)
+	}
+
+	migrations := allMigrations()
+	direction := DirectionUp
+	if target < current {
+		direction = DirectionDown
+		slices.Reverse(migrations)
+	}
+
+	var plan []PlannedStatement
+	for _, m := range migrations {
+		if direction == DirectionUp && (applied[m.Version] || m.Version > target) {
+			continue
+		}
+		if direction == DirectionDown && (!applied[m.Version] || m.Version <= target) {
+			continue
+		}
+		stmts, err := migrationStatements(m.Name, direction)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range stmts {
+			plan = append(plan, PlannedStatement{
+				Version:   m.Version,
+				Name:      m.Name,
+				Direction: direction,
+				SQL:       stmt,
+			})
+		}
+	}
+	return plan, nil
+}
+
+// migrationStatements returns the statements of the migration name for direction.
+// Registered Go migrations are represented by a single placeholder comment.
+func migrationStatements(name, direction string) ([]string, error) {
+	if path.Ext(name) == ".go" {
+		return []string{registeredPlanSQL}, nil
+	}
+	f, err := BaseFS.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration %s: %w", name, err)
+	}
+	defer f.Close() //nolint:errcheck // embedded files cannot fail to close
+
+	stmts, err := parseStatements(f, direction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse migration %s: %w", name, err)
+	}
+	return stmts, nil
+}
+
+// parseStatements splits a goose SQL migration into the statements goose would execute for direction.
+// It follows goose's rules for the annotations used in this package: statements end at a trailing
+// semicolon unless wrapped in StatementBegin/StatementEnd, and comments before a statement are dropped.
+func parseStatements(r io.Reader, direction string) ([]string, error) {
+	var (
+		stmts   []string
+		buf     strings.Builder
+		section string
+		inBlock bool
+	)
+	emit := func() {
+		if section == direction {
+			stmts = append(stmts, strings.TrimSpace(buf.String()))
+		}
+		buf.Reset()
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if section == "" && trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "--") && strings.Contains(line, "+goose") {
+			annotation := strings.TrimSpace(strings.Replace(strings.ReplaceAll(line, "--", ""), "+goose", "", 1))
+			switch {
+			case strings.EqualFold(annotation, "Up"):
+				section = DirectionUp
+			case strings.EqualFold(annotation, "Down"):
+				if strings.TrimSpace(buf.String()) != "" {
+					return nil, errors.New("unfinished statement before Down annotation")
+				}
+				section = DirectionDown
+			case strings.EqualFold(annotation, "StatementBegin"):
+				inBlock = true
+			case strings.EqualFold(annotation, "StatementEnd"):
+				if !inBlock {
+					return nil, errors.New("StatementEnd without StatementBegin")
+				}
+				inBlock = false
+				emit()
+			case strings.EqualFold(annotation, "NO TRANSACTION"):
+			default:
+				return nil, fmt.Errorf("unsupported annotation %q", annotation)
+			}
+			continue
+		}
+		if buf.Len() == 0 && (strings.HasPrefix(trimmed, "--") || line == "") {
+			continue
+		}
+		if section == "" {
+			return nil, errors.New("migration must start with an Up annotation")
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !inBlock && endsWithSemicolon(line) {
+			emit()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inBlock {
+		return nil, errors.New("missing StatementEnd annotation")
+	}
+	if strings.TrimSpace(buf.String()) != "" {
+		return nil, errors.New("unfinished statement, missing semicolon?")
+	}
+	return stmts, nil
+}
+
+// endsWithSemicolon reports whether the last word before any -- comment on line ends with a semicolon.
+func endsWithSemicolon(line string) bool {
+	prev := ""
+	for _, word := range strings.Fields(line) {
+		if strings.HasPrefix(word, "--") {
+			break
+		}
+		prev = word
+	}
+	return strings.HasSuffix(prev, ";")
+}
+
+// printPlan writes plan to w grouped by migration.
+func printPlan(w io.Writer, plan []PlannedStatement) error {
+	var last string
+	for _, stmt := range plan {
+		if header := stmt.Name + " " + stmt.Direction; header != last {
+			if _, err := fmt.Fprintf(w, "-- %s\n", header); err != nil {
+				return err
+			}
+			last = header
+		}
+		if _, err := fmt.Fprintf(w, "%s\n\n", stmt.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dryRun prints the plan for a goose command to w instead of executing it.
+func dryRun(ctx context.Context, gooseArgs []string, db *sql.DB, w io.Writer) error {
+	var args []string
+	for _, arg := range gooseArgs {
+		if !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+		}
+	}
+	if len(args) == 0 {
+		return errors.New("command not provided")
+	}
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+	target, err := commandTarget(args, applied)
+	if err != nil {
+		return err
+	}
+	plan, err := planTo(applied, target)
+	if err != nil {
+		return err
+	}
+	return printPlan(w, plan)
+}
+
+// commandTarget returns the version a goose command would migrate to.
+// Up commands never move below the current version and down commands never move above it.
+func commandTarget(args []string, applied map[int64]bool) (int64, error) {
+	var current, previous int64
+	for version := range applied {
+		current = max(current, version)
+	}
+	for version := range applied {
+		if version < current {
+			previous = max(previous, version)
+		}
+	}
+
+	var target int64
+	switch args[0] {
+	case "up":
+		return max(LatestVersion(), current), nil
+	case "down":
+		return previous, nil
+	case "up-to", "down-to":
+		if len(args) < 2 {
+			return 0, fmt.Errorf("%s requires a version", args[0])
+		}
+		var err error
+		target, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil || target < 0 {
+			return 0, fmt.Errorf("invalid target version %q", args[1])
+		}
+	default:
+		return 0, fmt.Errorf("dry run is not supported for %q", args[0])
+	}
+	if args[0] == "up-to" {
+		return max(target, current), nil
+	}
+	return min(target, current), nil
+}
@@ -0,0 +1,171 @@
+package migrations
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatements(t *testing.T) {
+	t.Parallel()
+
+	migration := `-- leading comment
+-- +goose Up
+CREATE TABLE a (x String);
+-- comment before a statement
+ALTER TABLE a
+    ADD COLUMN y String; -- trailing comment
+-- +goose StatementBegin
+-- dropped comment
+SELECT 1;
+SELECT 2;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE a;
+-- +goose StatementEnd
+`
+	up, err := parseStatements(strings.NewReader(migration), DirectionUp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE a (x String);",
+		"ALTER TABLE a\n    ADD COLUMN y String; -- trailing comment",
+		"SELECT 1;\nSELECT 2;",
+	}, up)
+
+	down, err := parseStatements(strings.NewReader(migration), DirectionDown)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DROP TABLE a;"}, down)
+}
+
+func TestParseStatements_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		migration string
+	}{
+		{name: "no up annotation", migration: "SELECT 1;\n"},
+		{name: "missing statement end", migration: "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n"},
+		{name: "missing semicolon", migration: "-- +goose Up\nSELECT 1\n"},
+		{name: "unknown annotation", migration: "-- +goose Up\n-- +goose ENVSUB ON\nSELECT 1;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseStatements(strings.NewReader(tt.migration), DirectionUp)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseStatements_EmbeddedMigrations(t *testing.T) {
+	t.Parallel()
+
+	for _, m := range allMigrations() {
+		for _, direction := range []string{DirectionUp, DirectionDown} {
+			stmts, err := migrationStatements(m.Name, direction)
+			require.NoError(t, err, m.Name)
+			assert.NotEmpty(t, stmts, "%s has no %s statements", m.Name, direction)
+		}
+	}
+
+	stmts, err := migrationStatements("00008_add_voids_id_migration.sql", DirectionUp)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	assert.True(t, strings.HasPrefix(stmts[1], "ALTER TABLE cloud_event ADD INDEX IF NOT EXISTS idx_event_type"), "leading comments must be dropped: %q", stmts[1])
+}
+
+func TestPlanTo(t *testing.T) {
+	t.Parallel()
+
+	appliedThrough := func(version int64) map[int64]bool {
+		applied := map[int64]bool{}
+		for v := int64(1); v <= version; v++ {
+			applied[v] = true
+		}
+		return applied
+	}
+	versions := func(plan []PlannedStatement) []int64 {
+		var out []int64
+		for _, stmt := range plan {
+			if len(out) == 0 || out[len(out)-1] != stmt.Version {
+				out = append(out, stmt.Version)
+			}
+		}
+		return out
+	}
+
+	plan, err := planTo(nil, LatestVersion())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, versions(plan))
+	for _, stmt := range plan {
+		assert.Equal(t, DirectionUp, stmt.Direction)
+	}
+
+	plan, err = planTo(appliedThrough(8), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9, 10}, versions(plan))
+
+	plan, err = planTo(appliedThrough(LatestVersion()), LatestVersion())
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+
+	plan, err = planTo(appliedThrough(11), 9)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{11, 10}, versions(plan))
+	for _, stmt := range plan {
+		assert.Equal(t, DirectionDown, stmt.Direction)
+	}
+	assert.Equal(t, "DROP VIEW IF EXISTS cloud_event_latest_mv;", plan[0].SQL)
+}
+
+func TestCommandTarget(t *testing.T) {
+	t.Parallel()
+
+	applied := map[int64]bool{1: true, 2: true, 3: true, 4: true, 5: true}
+	tests := []struct {
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{args: []string{"up"}, want: LatestVersion()},
+		{args: []string{"up-to", "7"}, want: 7},
+		{args: []string{"up-to", "2"}, want: 5},
+		{args: []string{"down"}, want: 4},
+		{args: []string{"down-to", "2"}, want: 2},
+		{args: []string{"down-to", "9"}, want: 5},
+		{args: []string{"up-to"}, wantErr: true},
+		{args: []string{"down-to", "x"}, wantErr: true},
+		{args: []string{"redo"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			t.Parallel()
+			got, err := commandTarget(tt.args, applied)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := printPlan(&buf, []PlannedStatement{
+		{Version: 1, Name: "00001_a.sql", Direction: DirectionUp, SQL: "SELECT 1;"},
+		{Version: 1, Name: "00001_a.sql", Direction: DirectionUp, SQL: "SELECT 2;"},
+		{Version: 2, Name: "00002_b.sql", Direction: DirectionUp, SQL: "SELECT 3;"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "-- 00001_a.sql up\nSELECT 1;\n\nSELECT 2;\n\n-- 00002_b.sql up\nSELECT 3;\n\n", buf.String())
+}
@@ -0,0 +1,193 @@
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// MinRegisteredVersion is the lowest version a consumer may register.
+// Versions below it are reserved for the migrations embedded in this package.
+const MinRegisteredVersion int64 = 1000
+
+// registeredPlanSQL stands in for the statements of a registered Go migration in a plan.
+const registeredPlanSQL = "-- registered Go migration; its statements are only known when it runs"
+
+// MigrationFunc runs one direction of a registered migration inside a transaction.
+type MigrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+type registeredMigration struct {
+	version  int64
+	fileName string
+	up       MigrationFunc
+	down     MigrationFunc
+}
+
+// registry holds the migrations added with RegisterMigration.
+var registry struct {
+	sync.Mutex
+	migrations map[int64]registeredMigration
+}
+
+// RegisterMigration adds a consumer migration that RunGoose applies after the embedded ones.
+// It must be called before RunGoose. version must be at least MinRegisteredVersion and unique,
+// and name must be non-empty and must not contain path separators. Either func may be nil.
+func RegisterMigration(version int64, name string, up, down MigrationFunc) error {
+	if version < MinRegisteredVersion {
+		return fmt.Errorf("migration version %d is reserved, registered versions must be at least %d", version, MinRegisteredVersion)
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid migration name %q", name)
+	}
+	if slices.ContainsFunc(embeddedMigrations(), func(m MigrationInfo) bool { return m.Version == version }) {
+		return fmt.Errorf("migration version %d conflicts with an embedded migration", version)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if existing, ok := registry.migrations[version]; ok {
+		return fmt.Errorf("migration version %d conflicts with registered migration %s", version, existing.fileName)
+	}
+	if registry.migrations == nil {
+		registry.migrations = make(map[int64]registeredMigration)
+	}
+	registry.migrations[version] = registeredMigration{
+		version:  version,
+		fileName: fmt.Sprintf("%05d_%s.go", version, name),
+		up:       up,
+		down:     down,
+	}
+	return nil
+}
+
+// registeredMigrations returns the registered migrations ordered by version.
+func registeredMigrations() []registeredMigration {
+	registry.Lock()
+	defer registry.Unlock()
+	return sortedRegistered()
+}
+
+// sortedRegistered returns the registered migrations ordered by version. The caller must hold the registry lock.
+func sortedRegistered() []registeredMigration {
+	migrations := make([]registeredMigration, 0, len(registry.migrations))
+	for _, m := range registry.migrations {
+		migrations = append(migrations, m)
+	}
+	slices.SortFunc(migrations, func(a, b registeredMigration) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	return migrations
+}
+
+// gooseMigrations converts the registered migrations for a goose provider.
+func gooseMigrations() []*goose.Migration {
+	var out []*goose.Migration
+	for _, m := range registeredMigrations() {
+		gm := goose.NewGoMigration(m.version, &goose.GoFunc{RunTx: m.up}, &goose.GoFunc{RunTx: m.down})
+		gm.Source = m.fileName
+		out = append(out, gm)
+	}
+	return out
+}
+
+// runGooseWithRegistered runs a goose command over the embedded and registered migrations.
+// migrate.RunGoose resets the global goose registry and so cannot see registered migrations.
+// A provider is used instead of the global registry so that concurrent callers, including other
+// packages' migrations in the same process, do not interfere. It supports the commands up,
+// up-by-one, up-to, down, down-to, redo, reset, status and version; flags other than -v are ignored.
+func runGooseWithRegistered(ctx context.Context, gooseArgs []string, db *sql.DB) error {
+	var args []string
+	verbose := false
+	for _, arg := range gooseArgs {
+		switch {
+		case arg == "-v":
+			verbose = true
+		case !strings.HasPrefix(arg, "-"):
+			args = append(args, arg)
+		}
+	}
+	if len(args) == 0 {
+		return errors.New("command not provided")
+	}
+	provider, err := newProvider(db, goose.WithVerbose(verbose))
+	if err != nil {
+		return err
+	}
+	if err := runProviderCommand(ctx, provider, args[0], args[1:], os.Stdout); err != nil {
+		return fmt.Errorf("failed to run goose command: %w", err)
+	}
+	return nil
+}
+
+// runProviderCommand runs a goose CLI command with provider.
+func runProviderCommand(ctx context.Context, provider *goose.Provider, command string, args []string, w io.Writer) error {
+	version := func() (int64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%s requires a version argument", command)
+		}
+		return strconv.ParseInt(args[0], 10, 64)
+	}
+	var err error
+	switch command {
+	case "up":
+		_, err = provider.Up(ctx)
+	case "up-by-one":
+		_, err = provider.UpByOne(ctx)
+	case "up-to":
+		var v int64
+		if v, err = version(); err == nil {
+			_, err = provider.UpTo(ctx, v)
+		}
+	case "down":
+		_, err = provider.Down(ctx)
+	case "down-to":
+		var v int64
+		if v, err = version(); err == nil {
+			_, err = provider.DownTo(ctx, v)
+		}
+	case "redo":
+		if _, err = provider.Down(ctx); err == nil {
+			_, err = provider.UpByOne(ctx)
+		}
+	case "reset":
+		_, err = provider.DownTo(ctx, 0)
+	case "status":
+		var statuses []*goose.MigrationStatus
+		if statuses, err = provider.Status(ctx); err == nil {
+			err = printStatus(w, statuses)
+		}
+	case "version":
+		var v int64
+		if v, err = provider.GetDBVersion(ctx); err == nil {
+			_, err = fmt.Fprintf(w, "goose: version %d\n", v)
+		}
+	default:
+		err = fmt.Errorf("unsupported command %q", command)
+	}
+	return err
+}
+
+// printStatus writes one line per migration with its apply time, like goose's status command.
+func printStatus(w io.Writer, statuses []*goose.MigrationStatus) error {
+	for _, st := range statuses {
+		applied := "Pending"
+		if st.State == goose.StateApplied {
+			applied = st.AppliedAt.UTC().Format(time.DateTime)
+		}
+		if _, err := fmt.Fprintf(w, "%-24s -- %s\n", applied, st.Source.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DIMO-Network/clickhouse-infra/pkg/connect/config"
+	"github.com/DIMO-Network/clickhouse-infra/pkg/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetRegistry removes every registered migration when t finishes.
+// Tests that register migrations must not run in parallel.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		registry.migrations = nil
+	})
+}
+
+func TestRegisterMigration(t *testing.T) {
+	resetRegistry(t)
+
+	require.NoError(t, RegisterMigration(1001, "second", nil, nil))
+	require.NoError(t, RegisterMigration(1000, "first", nil, nil))
+
+	assert.Equal(t, int64(1001), LatestVersion())
+	migrations := allMigrations()
+	assert.Equal(t, []MigrationInfo{
+		{Version: 1000, Name: "01000_first.go"},
+		{Version: 1001, Name: "01001_second.go"},
+	}, migrations[len(migrations)-2:])
+
+	plan, err := planTo(map[int64]bool{1: true}, 1000)
+	require.NoError(t, err)
+	last := plan[len(plan)-1]
+	assert.Equal(t, PlannedStatement{Version: 1000, Name: "01000_first.go", Direction: DirectionUp, SQL: registeredPlanSQL}, last)
+}
+
+func TestRegisterMigration_Invalid(t *testing.T) {
+	resetRegistry(t)
+
+	require.NoError(t, RegisterMigration(1000, "first", nil, nil))
+
+	tests := []struct {
+		name    string
+		version int64
+		migName string
+	}{
+		{name: "reserved version", version: 11, migName: "reserved"},
+		{name: "below minimum", version: MinRegisteredVersion - 1, migName: "low"},
+		{name: "duplicate version", version: 1000, migName: "again"},
+		{name: "empty name", version: 1002, migName: ""},
+		{name: "path in name", version: 1003, migName: "a/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterMigration(tt.version, tt.migName, nil, nil)
+			require.Error(t, err)
+		})
+	}
+	assert.Len(t, registeredMigrations(), 1)
+}
+
+func TestMigration_Registered(t *testing.T) {
+	resetRegistry(t)
+
+	ctx := context.Background()
+	chcontainer, err := container.CreateClickHouseContainer(ctx, config.Settings{})
+	require.NoError(t, err, "Failed to create clickhouse container")
+
+	defer chcontainer.Terminate(ctx)
+
+	db, err := chcontainer.GetClickhouseAsDB()
+	require.NoError(t, err, "Failed to get clickhouse db")
+
+	embeddedLatest := LatestVersion()
+	err = RegisterMigration(1000, "consumer_table",
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "CREATE TABLE consumer_table (id String) ENGINE = MergeTree ORDER BY id")
+			return err
+		},
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DROP TABLE consumer_table")
+			return err
+		},
+	)
+	require.NoError(t, err)
+
+	pending, err := Pending(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, pending, int(embeddedLatest)+1)
+	assert.Equal(t, MigrationInfo{Version: 1000, Name: "01000_consumer_table.go"}, pending[len(pending)-1])
+
+	err = RunGoose(ctx, []string{"up", "-v"}, db)
+	require.NoError(t, err, "Failed to run migration")
+
+	current, err := CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), current)
+
+	upToDate, err := IsUpToDate(ctx, db)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	var exists uint8
+	err = db.QueryRowContext(ctx, "EXISTS TABLE consumer_table").Scan(&exists)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), exists)
+
+	err = RunGoose(ctx, []string{"down", "-v"}, db)
+	require.NoError(t, err, "Failed to roll back registered migration")
+
+	current, err = CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, embeddedLatest, current)
+
+	err = db.QueryRowContext(ctx, "EXISTS TABLE consumer_table").Scan(&exists)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), exists)
+
+	require.NoError(t, db.Close())
+}
+
+func TestRunGooseWithRegistered_Args(t *testing.T) {
+	resetRegistry(t)
+	require.NoError(t, RegisterMigration(1000, "first", nil, nil))
+
+	// The connection is never opened because every case fails before touching the database.
+	db, err := sql.Open("clickhouse", "clickhouse://127.0.0.1:1/default")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.ErrorContains(t, RunGoose(ctx, []string{"-v"}, db), "command not provided")
+	require.ErrorContains(t, RunGoose(ctx, []string{"bogus"}, db), `unsupported command "bogus"`)
+	require.ErrorContains(t, RunGoose(ctx, []string{"up-to", "-v"}, db), "requires a version argument")
+}
@@ -0,0 +1,151 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/pressly/goose/v3"
+)
+
+// MigrationInfo identifies a single migration.
+type MigrationInfo struct {
+	Version int64
+	Name    string
+}
+
+// newProvider returns a goose provider over the embedded and registered migrations.
+// The global goose registry is ignored because RunGoose resets it before every run,
+// so migrations registered there would never be applied.
+// opts are applied after the options selecting those migrations.
+func newProvider(db *sql.DB, opts ...goose.ProviderOption) (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectClickHouse, db, BaseFS, append([]goose.ProviderOption{
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithGoMigrations(gooseMigrations()...),
+	}, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create goose provider: %w", err)
+	}
+	return provider, nil
+}
+
+// CurrentVersion returns the highest migration version applied to db, or 0 if no migration
+// has run. It only reads: a database without the goose version table is left untouched.
+func CurrentVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	exists, err := versionTableExists(ctx, db)
+	if err != nil || !exists {
+		return 0, err
+	}
+	provider, err := newProvider(db)
+	if err != nil {
+		return 0, err
+	}
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestVersion returns the highest migration version known to this package,
+// including migrations added with RegisterMigration.
+func LatestVersion() int64 {
+	var latest int64
+	for _, m := range allMigrations() {
+		latest = max(latest, m.Version)
+	}
+	return latest
+}
+
+// IsUpToDate reports whether every known migration has been applied to db.
+// Like CurrentVersion, it does not create the goose version table.
+func IsUpToDate(ctx context.Context, db *sql.DB) (bool, error) {
+	exists, err := versionTableExists(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return len(allMigrations()) == 0, nil
+	}
+	provider, err := newProvider(db)
+	if err != nil {
+		return false, err
+	}
+	pending, err := provider.HasPending(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for pending migrations: %w", err)
+	}
+	return !pending, nil
+}
+
+// Pending returns the migrations that have not been applied to db, ordered by version.
+// Like CurrentVersion, it does not create the goose version table.
+func Pending(ctx context.Context, db *sql.DB) ([]MigrationInfo, error) {
+	exists, err := versionTableExists(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return allMigrations(), nil
+	}
+	provider, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+	var pending []MigrationInfo
+	for _, status := range statuses {
+		if status.State != goose.StatePending {
+			continue
+		}
+		pending = append(pending, MigrationInfo{
+			Version: status.Source.Version,
+			Name:    path.Base(status.Source.Path),
+		})
+	}
+	return pending, nil
+}
+
+// versionTableExists reports whether the goose version table exists in the current database.
+// goose creates the table on its first query, so the read-only helpers check for it first.
+func versionTableExists(ctx context.Context, db *sql.DB) (bool, error) {
+	var count uint64
+	err := db.QueryRowContext(ctx,
+		"SELECT count() FROM system.tables WHERE database = currentDatabase() AND name = ?",
+		goose.DefaultTablename,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for the goose version table: %w", err)
+	}
+	return count > 0, nil
+}
+
+// allMigrations lists the embedded and registered migrations ordered by version.
+// Registered versions are always above the embedded ones.
+func allMigrations() []MigrationInfo {
+	infos := embeddedMigrations()
+	for _, m := range registeredMigrations() {
+		infos = append(infos, MigrationInfo{Version: m.version, Name: m.fileName})
+	}
+	return infos
+}
+
+// embeddedMigrations lists the migrations embedded in BaseFS ordered by version.
+func embeddedMigrations() []MigrationInfo {
+	// The pattern is static and BaseFS is embedded, so Glob cannot fail.
+	names, _ := fs.Glob(BaseFS, "*.sql")
+	infos := make([]MigrationInfo, 0, len(names))
+	for _, name := range names {
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, MigrationInfo{Version: version, Name: name})
+	}
+	return infos
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyDBDriver answers the version table lookup as for a database that has never been
+// migrated and fails every other statement, recording what it was asked to run.
+type emptyDBDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *emptyDBDriver) Open(string) (driver.Conn, error) { return &emptyDBConn{d: d}, nil }
+
+func (d *emptyDBDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+}
+
+type emptyDBConn struct{ d *emptyDBDriver }
+
+func (c *emptyDBConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.record(query)
+	return nil, errors.New("unexpected statement")
+}
+func (c *emptyDBConn) Close() error              { return nil }
+func (c *emptyDBConn) Begin() (driver.Tx, error) { return nil, errors.New("unexpected transaction") }
+
+func (c *emptyDBConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query)
+	if !strings.Contains(query, "system.tables") {
+		return nil, errors.New("unexpected query")
+	}
+	return &countRows{}, nil
+}
+
+// countRows is a single row holding count() = 0.
+type countRows struct{ done bool }
+
+func (r *countRows) Columns() []string { return []string{"count()"} }
+func (r *countRows) Close() error      { return nil }
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(0)
+	return nil
+}
+
+func TestStatus_WithoutVersionTable(t *testing.T) {
+	ctx := context.Background()
+	drv := &emptyDBDriver{}
+	sql.Register("migrations-empty-db", drv)
+	db, err := sql.Open("migrations-empty-db", "")
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck
+
+	version, err := CurrentVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+
+	upToDate, err := IsUpToDate(ctx, db)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	pending, err := Pending(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, allMigrations(), pending)
+
+	// Only the lookup ran; nothing tried to create the version table.
+	require.Len(t, drv.queries, 3)
+	for _, query := range drv.queries {
+		assert.Contains(t, query, "system.tables")
+	}
+}
@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetentionType is the retention policy key whose duration applies to every event
+// type not listed in the policy. Every non-empty policy must include it.
+const DefaultRetentionType = ""
+
+// ApplyRetentionPolicy replaces the TTL of TableName with one DELETE rule per event type in
+// policy, e.g. 13 months for dimo.fingerprint and 7 years for dimo.attestation, plus a rule
+// applying the DefaultRetentionType duration to every other type. A non-empty policy without
+// a default is rejected, so no event type is kept forever by omission. Durations are applied
+// with second precision and must be at least one second. An empty policy removes the TTL.
+// Rows are deleted when ClickHouse next merges their parts; run ALTER TABLE ... MATERIALIZE TTL
+// to apply the rules to existing parts immediately.
+func ApplyRetentionPolicy(ctx context.Context, db *sql.DB, policy map[string]time.Duration) error {
+	stmt, err := retentionStatement(policy)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to apply retention policy: %w", err)
+	}
+	return nil
+}
+
+// retentionStatement builds the ALTER TABLE statement for policy.
+func retentionStatement(policy map[string]time.Duration) (string, error) {
+	if len(policy) == 0 {
+		return "ALTER TABLE " + TableName + " REMOVE TTL", nil
+	}
+	defaultRetention, ok := policy[DefaultRetentionType]
+	if !ok {
+		return "", errors.New("retention policy has no default, set DefaultRetentionType to cover unlisted event types")
+	}
+	types := make([]string, 0, len(policy))
+	for eventType, d := range policy {
+		if d < time.Second {
+			return "", fmt.Errorf("retention for event type %q must be at least one second, got %s", eventType, d)
+		}
+		if eventType != DefaultRetentionType {
+			types = append(types, eventType)
+		}
+	}
+	slices.Sort(types)
+
+	rules := make([]string, 0, len(policy))
+	for _, eventType := range types {
+		rules = append(rules, ttlRule(policy[eventType], TypeColumn+" = "+quoteString(eventType)))
+	}
+	if len(types) == 0 {
+		rules = append(rules, ttlRule(defaultRetention, ""))
+	} else {
+		quoted := make([]string, len(types))
+		for i, eventType := range types {
+			quoted[i] = quoteString(eventType)
+		}
+		rules = append(rules, ttlRule(defaultRetention, TypeColumn+" NOT IN ("+strings.Join(quoted, ", ")+")"))
+	}
+	return "ALTER TABLE " + TableName + " MODIFY TTL " + strings.Join(rules, ", "), nil
+}
+
+func ttlRule(d time.Duration, where string) string {
+	rule := "toDateTime(" + TimestampColumn + ") + INTERVAL " + strconv.FormatInt(int64(d/time.Second), 10) + " SECOND DELETE"
+	if where != "" {
+		rule += " WHERE " + where
+	}
+	return rule
+}
+
+// quoteString returns s as a ClickHouse string literal.
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
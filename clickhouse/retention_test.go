@@ -0,0 +1,57 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionStatement(t *testing.T) {
+	t.Parallel()
+
+	const day = 24 * time.Hour
+	stmt, err := retentionStatement(map[string]time.Duration{
+		"dimo.fingerprint":   395 * day,
+		"dimo.attestation":   7 * 365 * day,
+		DefaultRetentionType: 2 * 365 * day,
+		"it's\\odd":          time.Second,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE cloud_event MODIFY TTL "+
+		"toDateTime(event_time) + INTERVAL 220752000 SECOND DELETE WHERE event_type = 'dimo.attestation', "+
+		"toDateTime(event_time) + INTERVAL 34128000 SECOND DELETE WHERE event_type = 'dimo.fingerprint', "+
+		`toDateTime(event_time) + INTERVAL 1 SECOND DELETE WHERE event_type = 'it\'s\\odd', `+
+		`toDateTime(event_time) + INTERVAL 63072000 SECOND DELETE WHERE event_type NOT IN ('dimo.attestation', 'dimo.fingerprint', 'it\'s\\odd')`,
+		stmt)
+
+	stmt, err = retentionStatement(map[string]time.Duration{DefaultRetentionType: day})
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE cloud_event MODIFY TTL toDateTime(event_time) + INTERVAL 86400 SECOND DELETE", stmt)
+
+	stmt, err = retentionStatement(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE cloud_event REMOVE TTL", stmt)
+
+	_, err = retentionStatement(map[string]time.Duration{"dimo.status": time.Millisecond, DefaultRetentionType: day})
+	require.Error(t, err)
+
+	_, err = retentionStatement(map[string]time.Duration{"dimo.status": day})
+	require.ErrorContains(t, err, "no default")
+}
+
+func TestRetentionStatement_UnlistedTypeGetsDefault(t *testing.T) {
+	t.Parallel()
+
+	const day = 24 * time.Hour
+	stmt, err := retentionStatement(map[string]time.Duration{
+		"dimo.fingerprint":   395 * day,
+		DefaultRetentionType: 30 * day,
+	})
+	require.NoError(t, err)
+	// dimo.status is not listed, so only the default rule, which excludes listed types, matches it.
+	assert.True(t, strings.HasSuffix(stmt, "toDateTime(event_time) + INTERVAL 2592000 SECOND DELETE WHERE event_type NOT IN ('dimo.fingerprint')"), stmt)
+	assert.NotContains(t, stmt, "'dimo.status'")
+}
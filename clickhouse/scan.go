@@ -0,0 +1,67 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+)
+
+// HeaderSelectColumns is the column list ScanCloudEventHeader expects, in order.
+// Use it as the SELECT list of queries whose rows are scanned into headers.
+const HeaderSelectColumns = SubjectColumn + ", " +
+	TimestampColumn + ", " +
+	TypeColumn + ", " +
+	IDColumn + ", " +
+	SourceColumn + ", " +
+	ProducerColumn + ", " +
+	DataContentTypeColumn + ", " +
+	DataVersionColumn + ", " +
+	ExtrasColumn + ", " +
+	IndexKeyColumn + ", " +
+	SignatureColumn
+
+// ScanCloudEventHeader scans a row selected with HeaderSelectColumns into a header and returns
+// it with the row's index key. scan is typically the Scan method of a driver.Rows or sql.Rows.
+// Extras are decoded and fields without a dedicated column are restored from them; a
+// non-NULL signature column takes precedence over a signature kept in extras.
+func ScanCloudEventHeader(scan func(dest ...any) error) (*cloudevent.CloudEventHeader, string, error) {
+	var (
+		hdr       cloudevent.CloudEventHeader
+		eventTime time.Time
+		extras    string
+		indexKey  string
+		signature *string
+	)
+	err := scan(
+		&hdr.Subject,
+		&eventTime,
+		&hdr.Type,
+		&hdr.ID,
+		&hdr.Source,
+		&hdr.Producer,
+		&hdr.DataContentType,
+		&hdr.DataVersion,
+		&extras,
+		&indexKey,
+		&signature,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan cloud event header: %w", err)
+	}
+	hdr.Time = eventTime.UTC()
+	if extras != "" && extras != "{}" {
+		if err := json.Unmarshal([]byte(extras), &hdr.Extras); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal extras: %w", err)
+		}
+	}
+	cloudevent.RestoreNonColumnFields(&hdr)
+	if len(hdr.Extras) == 0 {
+		hdr.Extras = nil
+	}
+	if signature != nil {
+		hdr.Signature = *signature
+	}
+	return &hdr, indexKey, nil
+}
@@ -0,0 +1,90 @@
+package clickhouse
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScan returns a scan function that copies the slice produced by CloudEventToSlice
+// into the destinations, as the driver would for a row selected with HeaderSelectColumns.
+func fakeScan(t *testing.T, row []any) func(dest ...any) error {
+	t.Helper()
+	// Drop data_index_key and voids_id, which are not part of HeaderSelectColumns.
+	values := append(append([]any{}, row[:10]...), row[12])
+	return func(dest ...any) error {
+		require.Len(t, dest, len(values))
+		for i, v := range values {
+			switch d := dest[i].(type) {
+			case *string:
+				*d = v.(string)
+			case *time.Time:
+				*d = v.(time.Time)
+			case **string:
+				*d = v.(*string)
+			default:
+				t.Fatalf("unexpected destination %T", d)
+			}
+		}
+		return nil
+	}
+}
+
+func TestScanCloudEventHeader(t *testing.T) {
+	t.Parallel()
+
+	hdr := cloudevent.CloudEventHeader{
+		SpecVersion:     cloudevent.SpecVersion,
+		Type:            cloudevent.TypeStatus,
+		Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+		ID:              "1",
+		Time:            time.Date(2024, 6, 1, 12, 0, 0, 123456000, time.UTC),
+		DataContentType: "application/json",
+		DataVersion:     "v2",
+		Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+		Signature:       "0xdeadbeef",
+		RawEventID:      "raw-1",
+		DataSchema:      "https://example.com/schema",
+		Tags:            []string{"a"},
+		Extras:          map[string]any{"region": "eu"},
+	}
+	got, indexKey, err := ScanCloudEventHeader(fakeScan(t, CloudEventToSliceWithKey(&hdr, "key-1")))
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", indexKey)
+	assert.Equal(t, hdr, *got)
+
+	// A plain header round-trips without extras.
+	plain := cloudevent.CloudEventHeader{SpecVersion: cloudevent.SpecVersion, ID: "2", Time: hdr.Time}
+	got, _, err = ScanCloudEventHeader(fakeScan(t, CloudEventToSlice(&plain)))
+	require.NoError(t, err)
+	assert.Equal(t, plain, *got)
+}
+
+func TestScanCloudEventHeader_LegacySignature(t *testing.T) {
+	t.Parallel()
+
+	// Rows written before the signature column keep the signature in extras.
+	row := CloudEventToSlice(&cloudevent.CloudEventHeader{ID: "1"})
+	row[8] = `{"signature":"0xabc"}`
+	got, _, err := ScanCloudEventHeader(fakeScan(t, row))
+	require.NoError(t, err)
+	assert.Equal(t, "0xabc", got.Signature)
+	assert.Nil(t, got.Extras)
+}
+
+func TestScanCloudEventHeader_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := ScanCloudEventHeader(func(...any) error { return errors.New("boom") })
+	require.Error(t, err)
+
+	row := CloudEventToSlice(&cloudevent.CloudEventHeader{ID: "1"})
+	row[8] = `{not json`
+	_, _, err = ScanCloudEventHeader(fakeScan(t, row))
+	require.Error(t, err)
+}
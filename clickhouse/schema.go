@@ -0,0 +1,175 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const (
+	// SchemaDiffColumn marks a column that is missing, unexpected or of the wrong type.
+	SchemaDiffColumn = "column"
+	// SchemaDiffEngine marks a table engine mismatch.
+	SchemaDiffEngine = "engine"
+	// SchemaDiffSortingKey marks an ORDER BY mismatch.
+	SchemaDiffSortingKey = "sorting_key"
+	// SchemaDiffPartitionKey marks a PARTITION BY mismatch.
+	SchemaDiffPartitionKey = "partition_key"
+	// SchemaDiffIndex marks a data skipping index that is missing, unexpected or of the wrong type.
+	SchemaDiffIndex = "index"
+)
+
+// SchemaDiff is a single difference between the expected and actual definition of TableName.
+// An empty Expected or Actual means the object should not exist or does not exist.
+type SchemaDiff struct {
+	Kind     string
+	Name     string
+	Expected string
+	Actual   string
+}
+
+// SchemaReport is the result of VerifySchema.
+type SchemaReport struct {
+	Table string
+	Diffs []SchemaDiff
+}
+
+// OK reports whether the table matches the expected schema.
+func (r SchemaReport) OK() bool {
+	return len(r.Diffs) == 0
+}
+
+type tableSchema struct {
+	engine       string
+	sortingKey   string
+	partitionKey string
+	columns      []namedDefinition
+	indexes      []namedDefinition
+}
+
+type namedDefinition struct {
+	name       string
+	definition string
+}
+
+// expectedSchema is TableName as created by the latest migration.
+// It must be updated together with every migration that changes the table.
+var expectedSchema = tableSchema{
+	engine:       "ReplacingMergeTree",
+	sortingKey:   SubjectColumn + ", " + TimestampColumn + ", " + TypeColumn + ", " + SourceColumn + ", " + IDColumn,
+	partitionKey: "toYYYYMM(" + TimestampColumn + ")",
+	columns: []namedDefinition{
+		{SubjectColumn, "String"},
+		{TimestampColumn, "DateTime64(6, 'UTC')"},
+		{TypeColumn, "LowCardinality(String)"},
+		{IDColumn, "String"},
+		{SourceColumn, "LowCardinality(String)"},
+		{ProducerColumn, "String"},
+		{DataContentTypeColumn, "LowCardinality(String)"},
+		{DataVersionColumn, "LowCardinality(String)"},
+		{ExtrasColumn, "String"},
+		{IndexKeyColumn, "String"},
+		{DataIndexKeyColumn, "String"},
+		{VoidsIDColumn, "String"},
+		{SignatureColumn, "Nullable(String)"},
+	},
+	indexes: []namedDefinition{
+		{"idx_event_type", "set(0)"},
+		{"idx_id", "bloom_filter"},
+		{"idx_index_key", "bloom_filter"},
+		{"idx_source", "set(0)"},
+	},
+}
+
+// VerifySchema compares the TableName table in the current database of db against the
+// schema this package writes to: columns and their types, engine, ORDER BY, PARTITION BY
+// and data skipping indexes. Differences are returned in the report; an error is only
+// returned if the table cannot be introspected.
+func VerifySchema(ctx context.Context, db *sql.DB) (SchemaReport, error) {
+	actual, err := loadTableSchema(ctx, db, TableName)
+	if err != nil {
+		return SchemaReport{}, err
+	}
+	return SchemaReport{Table: TableName, Diffs: compareSchema(expectedSchema, actual)}, nil
+}
+
+// loadTableSchema reads the definition of table from the system tables.
+func loadTableSchema(ctx context.Context, db *sql.DB, table string) (tableSchema, error) {
+	var schema tableSchema
+	err := db.QueryRowContext(ctx,
+		"SELECT engine, sorting_key, partition_key FROM system.tables WHERE database = currentDatabase() AND name = ?", table).
+		Scan(&schema.engine, &schema.sortingKey, &schema.partitionKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return schema, fmt.Errorf("table %s does not exist", table)
+	}
+	if err != nil {
+		return schema, fmt.Errorf("failed to get table %s: %w", table, err)
+	}
+	schema.columns, err = queryDefinitions(ctx, db,
+		"SELECT name, type FROM system.columns WHERE database = currentDatabase() AND table = ? ORDER BY position", table)
+	if err != nil {
+		return schema, fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+	schema.indexes, err = queryDefinitions(ctx, db,
+		"SELECT name, type_full FROM system.data_skipping_indices WHERE database = currentDatabase() AND table = ? ORDER BY name", table)
+	if err != nil {
+		return schema, fmt.Errorf("failed to get indexes of %s: %w", table, err)
+	}
+	return schema, nil
+}
+
+func queryDefinitions(ctx context.Context, db *sql.DB, query, table string) ([]namedDefinition, error) {
+	rows, err := db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // the error from rows.Err is returned instead
+	var defs []namedDefinition
+	for rows.Next() {
+		var def namedDefinition
+		if err := rows.Scan(&def.name, &def.definition); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// compareSchema returns the differences between expected and actual in a stable order:
+// table properties first, then columns and indexes in expected order followed by unexpected ones.
+func compareSchema(expected, actual tableSchema) []SchemaDiff {
+	var diffs []SchemaDiff
+	property := func(kind, want, got string) {
+		if want != got {
+			diffs = append(diffs, SchemaDiff{Kind: kind, Expected: want, Actual: got})
+		}
+	}
+	property(SchemaDiffEngine, expected.engine, actual.engine)
+	property(SchemaDiffSortingKey, expected.sortingKey, actual.sortingKey)
+	property(SchemaDiffPartitionKey, expected.partitionKey, actual.partitionKey)
+	diffs = append(diffs, compareDefinitions(SchemaDiffColumn, expected.columns, actual.columns)...)
+	diffs = append(diffs, compareDefinitions(SchemaDiffIndex, expected.indexes, actual.indexes)...)
+	return diffs
+}
+
+func compareDefinitions(kind string, expected, actual []namedDefinition) []SchemaDiff {
+	actualByName := make(map[string]string, len(actual))
+	for _, def := range actual {
+		actualByName[def.name] = def.definition
+	}
+	var diffs []SchemaDiff
+	seen := make(map[string]bool, len(expected))
+	for _, def := range expected {
+		seen[def.name] = true
+		if got := actualByName[def.name]; got != def.definition {
+			diffs = append(diffs, SchemaDiff{Kind: kind, Name: def.name, Expected: def.definition, Actual: got})
+		}
+	}
+	for _, def := range actual {
+		if !seen[def.name] {
+			diffs = append(diffs, SchemaDiff{Kind: kind, Name: def.name, Actual: def.definition})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,32 @@
+package clickhouse
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSchema(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, compareSchema(expectedSchema, expectedSchema))
+
+	drifted := expectedSchema
+	drifted.engine = "MergeTree"
+	drifted.partitionKey = ""
+	drifted.columns = slices.Clone(expectedSchema.columns)
+	drifted.columns[1].definition = "DateTime64(3, 'UTC')"
+	drifted.columns = slices.DeleteFunc(drifted.columns, func(def namedDefinition) bool { return def.name == VoidsIDColumn })
+	drifted.columns = append(drifted.columns, namedDefinition{"hotfix", "String"})
+	drifted.indexes = expectedSchema.indexes[1:]
+
+	assert.Equal(t, []SchemaDiff{
+		{Kind: SchemaDiffEngine, Expected: "ReplacingMergeTree", Actual: "MergeTree"},
+		{Kind: SchemaDiffPartitionKey, Expected: "toYYYYMM(event_time)"},
+		{Kind: SchemaDiffColumn, Name: TimestampColumn, Expected: "DateTime64(6, 'UTC')", Actual: "DateTime64(3, 'UTC')"},
+		{Kind: SchemaDiffColumn, Name: VoidsIDColumn, Expected: "String"},
+		{Kind: SchemaDiffColumn, Name: "hotfix", Actual: "String"},
+		{Kind: SchemaDiffIndex, Name: "idx_event_type", Expected: "set(0)"},
+	}, compareSchema(expectedSchema, drifted))
+}
@@ -0,0 +1,58 @@
+package cloudevent
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Clone returns a deep copy of c. Tags and Extras are copied, including the nested objects and
+// arrays that decoding JSON produces, so the copy can be mutated without affecting c.
+func (c CloudEventHeader) Clone() CloudEventHeader {
+	c.Tags = slices.Clone(c.Tags)
+	if c.Extras != nil {
+		c.Extras = cloneValue(c.Extras).(map[string]any)
+	}
+	return c
+}
+
+// Clone returns a copy of c with a deep-copied header. When A is json.RawMessage the data bytes
+// are copied as well; any other data value is copied as an ordinary Go assignment.
+func (c CloudEvent[A]) Clone() CloudEvent[A] {
+	c.CloudEventHeader = c.CloudEventHeader.Clone()
+	if raw, ok := any(c.Data).(json.RawMessage); ok {
+		c.Data = any(slices.Clone(raw)).(A)
+	}
+	return c
+}
+
+// cloneValue deep-copies the composite types found in decoded JSON. Other values are returned as is.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if v == nil {
+			return v
+		}
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneValue(e)
+		}
+		return out
+	case []any:
+		if v == nil {
+			return v
+		}
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	case []string:
+		return slices.Clone(v)
+	case []byte:
+		return slices.Clone(v)
+	case json.RawMessage:
+		return slices.Clone(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,42 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEventHeader_Clone(t *testing.T) {
+	t.Parallel()
+
+	var orig cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"1","tags":["a"],"meta":{"device":{"id":"x"},"list":[{"k":1}]},"data":{"speed":1}}`), &orig))
+
+	clone := orig.Clone()
+	require.Equal(t, orig, clone)
+
+	clone.Tags[0] = "b"
+	clone.Extras["new"] = true
+	meta := clone.Extras["meta"].(map[string]any)
+	meta["device"].(map[string]any)["id"] = "y"
+	meta["list"].([]any)[0].(map[string]any)["k"] = 2
+	clone.Data[2] = 'X'
+
+	assert.Equal(t, []string{"a"}, orig.Tags)
+	assert.NotContains(t, orig.Extras, "new")
+	assert.Equal(t, map[string]any{
+		"device": map[string]any{"id": "x"},
+		"list":   []any{map[string]any{"k": float64(1)}},
+	}, orig.Extras["meta"])
+	assert.JSONEq(t, `{"speed":1}`, string(orig.Data))
+
+	empty := cloudevent.CloudEventHeader{}.Clone()
+	assert.Nil(t, empty.Extras)
+	assert.Nil(t, empty.Tags)
+
+	typed := cloudevent.CloudEvent[TestData]{Data: TestData{Message: "m"}}
+	assert.Equal(t, typed, typed.Clone())
+}
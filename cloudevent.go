@@ -2,8 +2,9 @@
 package cloudevent
 
 import (
+	"crypto/rand"
 	"encoding/json"
-	"mime"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -81,6 +82,137 @@ type CloudEvent[A any] struct {
 // It supports both "data" and "data_base64" (CloudEvents JSON spec).
 type RawEvent = CloudEvent[json.RawMessage]
 
+// HeaderOption sets an optional attribute on a header built by NewCloudEventHeader.
+type HeaderOption func(*CloudEventHeader) error
+
+// WithProducer sets the producer attribute.
+func WithProducer(producer string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.Producer = producer
+		return nil
+	}
+}
+
+// WithProducerDID validates d and sets the producer attribute to its string form.
+func WithProducerDID(d DID) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		return h.SetProducer(d)
+	}
+}
+
+// WithDataVersion sets the dataversion attribute.
+func WithDataVersion(version string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.DataVersion = version
+		return nil
+	}
+}
+
+// WithDataContentType sets the datacontenttype attribute.
+func WithDataContentType(contentType string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.DataContentType = contentType
+		return nil
+	}
+}
+
+// WithExtra sets an extension attribute. It fails if key names a header attribute or data.
+func WithExtra(key string, value any) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		return h.SetExtra(key, value)
+	}
+}
+
+// WithSource sets the source attribute.
+func WithSource(source string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.Source = source
+		return nil
+	}
+}
+
+// WithSubject sets the subject attribute.
+func WithSubject(subject string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.Subject = subject
+		return nil
+	}
+}
+
+// WithSubjectDID validates d and sets the subject attribute to its string form.
+func WithSubjectDID(d DID) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		return h.SetSubject(d)
+	}
+}
+
+// WithType sets the type attribute.
+func WithType(eventType string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.Type = eventType
+		return nil
+	}
+}
+
+// WithID replaces the generated ID.
+func WithID(id string) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.ID = id
+		return nil
+	}
+}
+
+// WithTime replaces the default time, which is when the header was built.
+func WithTime(t time.Time) HeaderOption {
+	return func(h *CloudEventHeader) error {
+		h.Time = t
+		return nil
+	}
+}
+
+// NewCloudEventHeader returns a header with a newly generated ID, the current UTC time and
+// SpecVersion, and the given source, subject and type, then applies opts in order.
+func NewCloudEventHeader(source, subject, eventType string, opts ...HeaderOption) (CloudEventHeader, error) {
+	return newHeader(append([]HeaderOption{WithSource(source), WithSubject(subject), WithType(eventType)}, opts...))
+}
+
+// New returns an event carrying data whose header is built like NewCloudEventHeader's from opts.
+// It fails if an option fails or if the resulting header does not pass Validate, so source,
+// subject and type must be set by options.
+func New[A any](data A, opts ...HeaderOption) (CloudEvent[A], error) {
+	h, err := newHeader(opts)
+	if err != nil {
+		return CloudEvent[A]{}, err
+	}
+	if err := h.Validate(); err != nil {
+		return CloudEvent[A]{}, err
+	}
+	return CloudEvent[A]{CloudEventHeader: h, Data: data}, nil
+}
+
+// MustNew is like New but panics on error.
+func MustNew[A any](data A, opts ...HeaderOption) CloudEvent[A] {
+	ev, err := New(data, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return ev
+}
+
+func newHeader(opts []HeaderOption) (CloudEventHeader, error) {
+	h := CloudEventHeader{
+		SpecVersion: SpecVersion,
+		ID:          rand.Text(),
+		Time:        time.Now().UTC(),
+	}
+	for _, opt := range opts {
+		if err := opt(&h); err != nil {
+			return CloudEventHeader{}, err
+		}
+	}
+	return h, nil
+}
+
 // BytesForSignature returns the bytes that were signed (wire form of data or data_base64) for a RawEvent.
 // Use for signature verification; not the same as Data when the CE used data_base64.
 func BytesForSignature(ev RawEvent) []byte {
@@ -91,10 +223,10 @@ func BytesForSignature(ev RawEvent) []byte {
 }
 
 // IsJSONDataContentType returns true if the MIME type indicates a JSON payload.
-// Matches "application/json" and any "+json" suffix type (e.g. "application/cloudevents+json").
+//
+// Deprecated: Use IsJSONContentType.
 func IsJSONDataContentType(ct string) bool {
-	parsed, _, err := mime.ParseMediaType(strings.TrimSpace(ct))
-	return err == nil && (parsed == "application/json" || strings.HasSuffix(parsed, "+json"))
+	return IsJSONContentType(ct)
 }
 
 // Equals returns true if the two CloudEventHeaders share the same IndexKey.
@@ -103,8 +235,49 @@ func (c *CloudEventHeader) Equals(other CloudEventHeader) bool {
 }
 
 // Key returns the unique identifier for the CloudEvent.
+// Time is formatted with time.RFC3339Nano, so events that differ only in sub-second time get
+// distinct keys. Keys generated before this used time.RFC3339; because RFC3339Nano drops a
+// zero fractional second, the two formats agree for whole-second times and differ only for
+// events with sub-second precision. See LegacyKey for resolving those older keys.
 func (c CloudEventHeader) Key() string {
-	timeStr := c.Time.Format(time.RFC3339)
+	return c.key(time.RFC3339Nano)
+}
+
+// LegacyKey returns the key earlier versions produced, which truncated Time to whole seconds.
+// Stored keys are never recomputed, so objects and rows written under a legacy key stay where
+// they are. A reader that derives a key from a header should try Key first and fall back to
+// LegacyKey when the two differ.
+func (c CloudEventHeader) LegacyKey() string {
+	return c.key(time.RFC3339)
+}
+
+// KeyParts are the header attributes a Key is built from.
+type KeyParts struct {
+	Subject string
+	Time    time.Time
+	Type    string
+	Source  string
+	ID      string
+}
+
+// ParseKey splits a key produced by Key or LegacyKey back into its parts. Components are
+// separated by '!', which DIDs, hex sources and event types never contain; only the final ID
+// may contain it. Object keys from the clickhouse package carry a one-character hash prefix
+// that must be removed first.
+func ParseKey(key string) (KeyParts, error) {
+	parts := strings.SplitN(key, "!", 5)
+	if len(parts) != 5 {
+		return KeyParts{}, fmt.Errorf("cloudevent: key %q has %d components, expected 5", key, len(parts))
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return KeyParts{}, fmt.Errorf("cloudevent: key %q has an invalid time: %w", key, err)
+	}
+	return KeyParts{Subject: parts[0], Time: t, Type: parts[2], Source: parts[3], ID: parts[4]}, nil
+}
+
+func (c CloudEventHeader) key(timeLayout string) string {
+	timeStr := c.Time.Format(timeLayout)
 	var b strings.Builder
 	b.Grow(len(c.Subject) + 1 + len(timeStr) + 1 + len(c.Type) + 1 + len(c.Source) + 1 + len(c.ID))
 	b.WriteString(c.Subject)
@@ -3,7 +3,11 @@ package cloudevent
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -15,76 +19,219 @@ var knownHeaderFields = map[string]struct{}{
 	"producer": {}, "signature": {}, "raweventid": {}, "tags": {},
 }
 
-// unmarshalHeader parses CloudEvent JSON with gjson and returns the populated
-// header, raw data bytes, and data_base64 string.
-func unmarshalHeader(data []byte) (CloudEventHeader, []byte, string, error) {
-	result := gjson.ParseBytes(data)
-	if !result.IsObject() {
-		return CloudEventHeader{}, nil, "", fmt.Errorf("cloudevent: expected JSON object")
+// envelopeFieldBits assigns each header attribute and data member a bit for duplicate detection.
+var envelopeFieldBits = func() map[string]uint16 {
+	bits := make(map[string]uint16, len(knownHeaderFields)+2)
+	for _, k := range append(slices.Sorted(maps.Keys(knownHeaderFields)), "data", "data_base64") {
+		bits[k] = 1 << len(bits)
 	}
+	return bits
+}()
 
-	var header CloudEventHeader
-	header.SpecVersion = SpecVersion
-	header.Type = result.Get("type").Str
-	header.Source = result.Get("source").Str
-	header.Subject = result.Get("subject").Str
-	header.ID = result.Get("id").Str
-	header.Producer = result.Get("producer").Str
-	header.DataContentType = result.Get("datacontenttype").Str
-	header.DataSchema = result.Get("dataschema").Str
-	header.DataVersion = result.Get("dataversion").Str
-	header.Signature = result.Get("signature").Str
-	header.RawEventID = result.Get("raweventid").Str
-
-	if tr := result.Get("time"); tr.Exists() {
-		if tr.Type != gjson.String {
-			return CloudEventHeader{}, nil, "", fmt.Errorf("cloudevent: time must be a string")
+// isReservedExtraKey reports whether k is a header attribute or data field and so cannot be an extra.
+func isReservedExtraKey(k string) bool {
+	_, known := knownHeaderFields[k]
+	return known || k == "data" || k == "data_base64"
+}
+
+// DecodeOption configures Unmarshal.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	useNumber          bool
+	skipDataValidation bool
+	strict             bool
+}
+
+// EventDataValidator is implemented by data types with invariants of their own. When a typed
+// CloudEvent is decoded and its Data implements EventDataValidator, either on the value or the
+// pointer receiver, ValidateEventData is called after Data is decoded and a failure is
+// returned as ErrMalformedData. It is not called when the event has no data.
+type EventDataValidator interface {
+	ValidateEventData() error
+}
+
+// WithoutDataValidation skips EventDataValidator, e.g. for tooling that salvages invalid events.
+func WithoutDataValidation() DecodeOption {
+	return func(o *decodeOptions) { o.skipDataValidation = true }
+}
+
+// WithUseNumber decodes numbers in Extras, including nested ones, as json.Number instead of
+// float64, so integers beyond 2^53 such as token IDs keep their exact value. MarshalJSON
+// writes json.Number values verbatim.
+func WithUseNumber() DecodeOption {
+	return func(o *decodeOptions) { o.useNumber = true }
+}
+
+// stringHeaderFields are the header attributes encoded as JSON strings.
+var stringHeaderFields = []string{
+	"specversion", "type", "source", "subject", "id", "time", "datacontenttype",
+	"dataschema", "dataversion", "producer", "signature", "raweventid",
+}
+
+// checkStrict reports every way the envelope deviates from CloudEvents 1.0: a missing id,
+// source or type, a specversion other than SpecVersion, or a string attribute or tags of
+// the wrong JSON type. The lenient decoder silently zeroes such fields.
+func checkStrict(result gjson.Result) error {
+	var errs []error
+	for _, k := range stringHeaderFields {
+		if v := result.Get(k); v.Exists() && v.Type != gjson.String {
+			errs = append(errs, fmt.Errorf("%s must be a string", k))
 		}
-		t, err := time.Parse(time.RFC3339Nano, tr.Str)
-		if err != nil {
-			return CloudEventHeader{}, nil, "", fmt.Errorf("cloudevent: invalid time: %w", err)
+	}
+	for _, k := range []string{"id", "source", "type"} {
+		if result.Get(k).Str == "" {
+			errs = append(errs, fmt.Errorf("%s is required", k))
 		}
-		header.Time = t
 	}
+	if v := result.Get("specversion"); v.Type == gjson.String && v.Str != SpecVersion {
+		errs = append(errs, fmt.Errorf("specversion must be %q, got %q", SpecVersion, v.Str))
+	}
+	if v := result.Get("tags"); v.Exists() {
+		if !v.IsArray() {
+			errs = append(errs, errors.New("tags must be an array"))
+		} else {
+			v.ForEach(func(_, tag gjson.Result) bool {
+				if tag.Type != gjson.String {
+					errs = append(errs, errors.New("tags must contain only strings"))
+					return false
+				}
+				return true
+			})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrMalformedEnvelope, errors.Join(errs...))
+}
 
-	if tr := result.Get("tags"); tr.Exists() && tr.IsArray() {
-		tags := make([]string, 0, int(tr.Get("#").Int()))
-		tr.ForEach(func(_, v gjson.Result) bool {
-			tags = append(tags, v.Str)
+// valueWithNumbers is gjson's Result.Value with numbers returned as json.Number.
+func valueWithNumbers(v gjson.Result) any {
+	switch v.Type {
+	case gjson.Number:
+		return json.Number(v.Raw)
+	case gjson.JSON:
+		if v.IsArray() {
+			arr := []any{}
+			v.ForEach(func(_, item gjson.Result) bool {
+				arr = append(arr, valueWithNumbers(item))
+				return true
+			})
+			return arr
+		}
+		obj := map[string]any{}
+		v.ForEach(func(key, item gjson.Result) bool {
+			obj[key.Str] = valueWithNumbers(item)
 			return true
 		})
-		header.Tags = tags
+		return obj
+	default:
+		return v.Value()
+	}
+}
+
+// unmarshalHeader parses CloudEvent JSON with gjson and returns the populated
+// header, raw data bytes, and data_base64 string.
+func unmarshalHeader(data []byte, opts decodeOptions) (CloudEventHeader, []byte, string, error) {
+	result := gjson.ParseBytes(data)
+	if !result.IsObject() {
+		return CloudEventHeader{}, nil, "", fmt.Errorf("%w: expected JSON object", ErrMalformedEnvelope)
+	}
+	if opts.strict {
+		if err := checkStrict(result); err != nil {
+			return CloudEventHeader{}, nil, "", err
+		}
 	}
 
-	// Extras: iterate all keys, skip known + data fields
+	var header CloudEventHeader
+	header.SpecVersion = SpecVersion
+	var (
+		dataRaw    []byte
+		dataBase64 string
+		err        error
+		// seen records the envelope members already assigned, by bit. A duplicated member
+		// is resolved to its first occurrence for attributes and its last for extras.
+		seen uint16
+	)
+	// A single pass over the members fills the attributes, data and Extras.
 	result.ForEach(func(key, value gjson.Result) bool {
 		k := key.Str
-		if k == "data" || k == "data_base64" {
-			return true
-		}
-		if _, known := knownHeaderFields[k]; known {
-			return true
+		if bit, ok := envelopeFieldBits[k]; ok {
+			if seen&bit != 0 {
+				return true
+			}
+			seen |= bit
 		}
-		if header.Extras == nil {
-			header.Extras = make(map[string]any)
+		switch k {
+		case "type":
+			header.Type = value.Str
+		case "source":
+			header.Source = value.Str
+		case "subject":
+			header.Subject = value.Str
+		case "id":
+			header.ID = value.Str
+		case "producer":
+			header.Producer = value.Str
+		case "datacontenttype":
+			header.DataContentType = value.Str
+		case "dataschema":
+			header.DataSchema = value.Str
+		case "dataversion":
+			header.DataVersion = value.Str
+		case "signature":
+			header.Signature = value.Str
+		case "raweventid":
+			header.RawEventID = value.Str
+		case "specversion":
+			// Always SpecVersion; the strict decoder checks the wire value.
+		case "time":
+			if value.Type != gjson.String {
+				err = fmt.Errorf("%w: time must be a string", ErrMalformedEnvelope)
+				return false
+			}
+			t, perr := time.Parse(time.RFC3339Nano, value.Str)
+			if perr != nil {
+				err = fmt.Errorf("%w: invalid time: %w", ErrMalformedEnvelope, perr)
+				return false
+			}
+			header.Time = t
+		case "tags":
+			if value.IsArray() {
+				tags := make([]string, 0, int(value.Get("#").Int()))
+				value.ForEach(func(_, v gjson.Result) bool {
+					tags = append(tags, v.Str)
+					return true
+				})
+				header.Tags = tags
+			}
+		case "data_base64":
+			if value.Type != gjson.String {
+				err = fmt.Errorf("%w: data_base64 must be a string", ErrMalformedEnvelope)
+				return false
+			}
+			dataBase64 = value.Str
+		case "data":
+			dataRaw = []byte(value.Raw)
+		default:
+			if !gjson.Valid(value.Raw) {
+				err = &ExtraError{Key: k, Err: fmt.Errorf("invalid JSON value %q", value.Raw)}
+				return false
+			}
+			if header.Extras == nil {
+				header.Extras = make(map[string]any)
+			}
+			if opts.useNumber {
+				header.Extras[k] = valueWithNumbers(value)
+			} else {
+				header.Extras[k] = value.Value()
+			}
 		}
-		header.Extras[k] = value.Value()
 		return true
 	})
-
-	// data_base64
-	var dataBase64 string
-	if db64 := result.Get("data_base64"); db64.Exists() {
-		if db64.Type != gjson.String {
-			return CloudEventHeader{}, nil, "", fmt.Errorf("cloudevent: data_base64 must be a string")
-		}
-		dataBase64 = db64.Str
-	}
-
-	// data (raw bytes)
-	var dataRaw []byte
-	if dr := result.Get("data"); dr.Exists() {
-		dataRaw = []byte(dr.Raw)
+	if err != nil {
+		return CloudEventHeader{}, nil, "", err
 	}
 
 	return header, dataRaw, dataBase64, nil
@@ -94,13 +241,38 @@ func unmarshalHeader(data []byte) (CloudEventHeader, []byte, string, error) {
 // It transparently handles both "data" and "data_base64" wire formats.
 // For RawEvent (CloudEvent[json.RawMessage]), Data is set to the raw payload bytes.
 func (c *CloudEvent[A]) UnmarshalJSON(data []byte) error {
-	header, dataRaw, dataBase64, err := unmarshalHeader(data)
+	return c.unmarshal(data, decodeOptions{})
+}
+
+// Unmarshal decodes a CloudEvent from JSON like UnmarshalJSON, configured by opts.
+// On error the returned event holds whatever was decoded, so the header of an event with
+// invalid data is still available for logging.
+func Unmarshal[A any](data []byte, opts ...DecodeOption) (CloudEvent[A], error) {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var ev CloudEvent[A]
+	err := ev.unmarshal(data, o)
+	return ev, err
+}
+
+// UnmarshalStrict decodes like Unmarshal but rejects envelopes the lenient decoder accepts:
+// a missing or empty id, source or type, a specversion other than "1.0", and attributes of the
+// wrong JSON type, which Unmarshal decodes as zero values. All violations are reported together,
+// wrapped in ErrMalformedEnvelope. Invalid times are rejected by both decoders.
+func UnmarshalStrict[A any](data []byte, opts ...DecodeOption) (CloudEvent[A], error) {
+	return Unmarshal[A](data, append(opts, func(o *decodeOptions) { o.strict = true })...)
+}
+
+func (c *CloudEvent[A]) unmarshal(data []byte, opts decodeOptions) error {
+	header, dataRaw, dataBase64, err := unmarshalHeader(data, opts)
 	if err != nil {
 		return err
 	}
 
 	if dataRaw != nil && dataBase64 != "" {
-		return fmt.Errorf("cloudevent: both \"data\" and \"data_base64\" present; only one allowed")
+		return fmt.Errorf("%w: both \"data\" and \"data_base64\" present; only one allowed", ErrMalformedEnvelope)
 	}
 	c.CloudEventHeader = header
 
@@ -109,7 +281,7 @@ func (c *CloudEvent[A]) UnmarshalJSON(data []byte) error {
 		if dataBase64 != "" {
 			decoded, err := base64.StdEncoding.DecodeString(dataBase64)
 			if err != nil {
-				return err
+				return fmt.Errorf("%w: invalid data_base64: %w", ErrMalformedData, err)
 			}
 			*ptr = decoded
 			c.DataBase64 = dataBase64
@@ -124,15 +296,40 @@ func (c *CloudEvent[A]) UnmarshalJSON(data []byte) error {
 	if dataBase64 != "" {
 		decoded, err := base64.StdEncoding.DecodeString(dataBase64)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: invalid data_base64: %w", ErrMalformedData, err)
 		}
 		if err := json.Unmarshal(decoded, &c.Data); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", ErrMalformedData, err)
 		}
 		c.DataBase64 = dataBase64
 	} else if dataRaw != nil {
 		if err := json.Unmarshal(dataRaw, &c.Data); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", ErrMalformedData, err)
+		}
+	} else {
+		return nil
+	}
+	if opts.skipDataValidation {
+		return nil
+	}
+	return validateEventData(&c.Data, c.ID)
+}
+
+// validateEventData calls ValidateEventData if dataPtr, a pointer to decoded data, implements
+// EventDataValidator. Method sets make this cover both value and pointer receivers. When the
+// data is itself a non-nil pointer, as for CloudEvent[*T], that pointer is checked instead.
+func validateEventData(dataPtr any, id string) error {
+	v, ok := dataPtr.(EventDataValidator)
+	if !ok {
+		if rv := reflect.ValueOf(dataPtr); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			if elem := rv.Elem(); elem.Kind() == reflect.Pointer && !elem.IsNil() {
+				v, ok = elem.Interface().(EventDataValidator)
+			}
+		}
+	}
+	if ok {
+		if err := v.ValidateEventData(); err != nil {
+			return fmt.Errorf("%w: event %q: %w", ErrMalformedData, id, err)
 		}
 	}
 	return nil
@@ -140,7 +337,7 @@ func (c *CloudEvent[A]) UnmarshalJSON(data []byte) error {
 
 // UnmarshalJSON implements custom JSON unmarshaling for CloudEventHeader.
 func (c *CloudEventHeader) UnmarshalJSON(data []byte) error {
-	header, _, _, err := unmarshalHeader(data)
+	header, _, _, err := unmarshalHeader(data, decodeOptions{})
 	if err != nil {
 		return err
 	}
@@ -153,6 +350,10 @@ func (c *CloudEventHeader) UnmarshalJSON(data []byte) error {
 func DecodeHeader(data []byte) (CloudEventHeader, error) {
 	var hdr CloudEventHeader
 	if err := json.Unmarshal(data, &hdr); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return CloudEventHeader{}, fmt.Errorf("%w: %w", ErrMalformedEnvelope, err)
+		}
 		return CloudEventHeader{}, err
 	}
 	return hdr, nil
@@ -0,0 +1,166 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal_UseNumber(t *testing.T) {
+	t.Parallel()
+
+	input := `{"specversion":"1.0","type":"dimo.status","source":"s","subject":"sub","id":"1","time":"2024-06-01T12:00:00Z","producer":"p",` +
+		`"vehicletokenid":18446744073709551615,"big":115792089237316195423570985008687907853269984665640564039457584007913129639935,` +
+		`"ratio":0.1,"nested":{"ids":[9007199254740993,"x",true,null]},"data":{"speed":1}}`
+
+	ev, err := cloudevent.Unmarshal[json.RawMessage]([]byte(input), cloudevent.WithUseNumber())
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), ev.Extras["vehicletokenid"])
+	assert.Equal(t, json.Number("0.1"), ev.Extras["ratio"])
+	assert.Equal(t, map[string]any{"ids": []any{json.Number("9007199254740993"), "x", true, nil}}, ev.Extras["nested"])
+	assert.JSONEq(t, `{"speed":1}`, string(ev.Data))
+
+	// Numbers are written back verbatim.
+	encoded, err := json.Marshal(ev)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"vehicletokenid":18446744073709551615`)
+	assert.Contains(t, string(encoded), `"big":115792089237316195423570985008687907853269984665640564039457584007913129639935`)
+	assert.Contains(t, string(encoded), `"ids":[9007199254740993,"x",true,null]`)
+
+	// The default keeps decoding numbers as float64.
+	var plain cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal([]byte(input), &plain))
+	assert.IsType(t, float64(0), plain.Extras["vehicletokenid"])
+
+	_, err = cloudevent.Unmarshal[json.RawMessage]([]byte(`[]`), cloudevent.WithUseNumber())
+	require.ErrorIs(t, err, cloudevent.ErrMalformedEnvelope)
+}
+
+type odometerData struct {
+	VIN      string  `json:"vin"`
+	Odometer float64 `json:"odometer"`
+}
+
+func (d odometerData) ValidateEventData() error {
+	if len(d.VIN) != 17 {
+		return errors.New("vin must have 17 characters")
+	}
+	if d.Odometer < 0 {
+		return errors.New("odometer cannot be negative")
+	}
+	return nil
+}
+
+func TestUnmarshal_ValidateEventData(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"id":"ok","type":"dimo.status","data":{"vin":"1HGCM82633A004352","odometer":10}}`
+	var ev cloudevent.CloudEvent[odometerData]
+	require.NoError(t, json.Unmarshal([]byte(valid), &ev))
+
+	invalid := `{"id":"bad","type":"dimo.status","data_base64":"eyJ2aW4iOiIxMjMiLCJvZG9tZXRlciI6MTB9"}`
+	err := json.Unmarshal([]byte(invalid), &ev)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	assert.Contains(t, err.Error(), `event "bad"`)
+	assert.Equal(t, "bad", ev.ID, "the header is populated for logging")
+	assert.Equal(t, "dimo.status", ev.Type)
+
+	got, err := cloudevent.Unmarshal[odometerData]([]byte(invalid))
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	assert.Equal(t, "bad", got.ID)
+
+	got, err = cloudevent.Unmarshal[odometerData]([]byte(invalid), cloudevent.WithoutDataValidation())
+	require.NoError(t, err)
+	assert.Equal(t, "123", got.Data.VIN)
+
+	// Events without data are not validated.
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"empty"}`), &cloudevent.CloudEvent[odometerData]{}))
+
+	// Pointer-receiver validators are found too.
+	var ptr cloudevent.CloudEvent[ptrValidated]
+	require.Error(t, json.Unmarshal([]byte(`{"id":"p","data":{}}`), &ptr))
+
+	// So are validators on pointer data, which is only checked when it is not null.
+	var ptrData cloudevent.CloudEvent[*odometerData]
+	err = json.Unmarshal([]byte(`{"id":"bad","data":{"vin":"123"}}`), &ptrData)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"null","data":null}`), &ptrData))
+
+	raw := cloudevent.RawEvent{CloudEventHeader: cloudevent.CloudEventHeader{ID: "bad"}, Data: json.RawMessage(`{"vin":"123"}`)}
+	_, err = cloudevent.ConvertData[*odometerData](raw)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	var odo *odometerData
+	require.ErrorIs(t, raw.DataAs(&odo), cloudevent.ErrMalformedData)
+}
+
+type ptrValidated struct{}
+
+func (*ptrValidated) ValidateEventData() error { return errors.New("always invalid") }
+
+func TestUnmarshalStrict(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"specversion":"1.0","id":"1","source":"s","type":"dimo.status","time":"2024-06-01T12:00:00Z","region":"eu","data":{"message":"m"}}`
+	ev, err := cloudevent.UnmarshalStrict[TestData]([]byte(valid))
+	require.NoError(t, err)
+	assert.Equal(t, "m", ev.Data.Message)
+	assert.Equal(t, "eu", ev.Extras["region"])
+
+	_, err = cloudevent.UnmarshalStrict[TestData]([]byte(`{"id":"1","source":"s","type":"t"}`))
+	require.NoError(t, err, "specversion may be omitted")
+
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"empty object":      {`{}`, []string{"id is required", "source is required", "type is required"}},
+		"wrong specversion": {`{"specversion":"0.3","id":"1","source":"s","type":"t"}`, []string{`specversion must be "1.0", got "0.3"`}},
+		"numeric id":        {`{"id":12,"source":"s","type":"t"}`, []string{"id must be a string", "id is required"}},
+		"tags not strings":  {`{"id":"1","source":"s","type":"t","tags":["a",1]}`, []string{"tags must contain only strings"}},
+		"invalid time":      {`{"id":"1","source":"s","type":"t","time":"yesterday"}`, []string{"invalid time"}},
+	}
+	for name, tt := range tests {
+		_, err := cloudevent.UnmarshalStrict[json.RawMessage]([]byte(tt.input))
+		require.ErrorIs(t, err, cloudevent.ErrMalformedEnvelope, name)
+		for _, want := range tt.want {
+			assert.ErrorContains(t, err, want, name)
+		}
+
+		// The lenient decoder accepts everything except the invalid time.
+		var lenient cloudevent.RawEvent
+		err = json.Unmarshal([]byte(tt.input), &lenient)
+		if name == "invalid time" {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err, name)
+		}
+	}
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	input, err := json.Marshal(benchmarkEvent())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		var ev cloudevent.RawEvent
+		if err := ev.UnmarshalJSON(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUnmarshalJSON_DuplicateMembers(t *testing.T) {
+	t.Parallel()
+
+	var ev cloudevent.RawEvent
+	require.NoError(t, ev.UnmarshalJSON([]byte(`{"id":"first","region":"a","data":{"n":1},"id":"second","region":"b","data":{"n":2}}`)))
+	assert.Equal(t, "first", ev.ID, "attributes keep their first occurrence")
+	assert.JSONEq(t, `{"n":1}`, string(ev.Data))
+	assert.Equal(t, "b", ev.Extras["region"], "extras keep their last occurrence")
+}
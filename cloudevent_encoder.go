@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
@@ -84,11 +86,16 @@ func (c *CloudEventHeader) marshalHeaderTo(buf *bytes.Buffer) error {
 		buf.WriteByte(']')
 	}
 
-	for k, v := range c.Extras {
+	// Extras are written in key order so that the same header always encodes to the same bytes.
+	for _, k := range slices.Sorted(maps.Keys(c.Extras)) {
+		// An extra named like an attribute would produce a duplicate JSON member; the attribute wins.
+		if isReservedExtraKey(k) {
+			continue
+		}
 		buf.WriteByte(',')
 		appendJSONString(buf, k)
 		buf.WriteByte(':')
-		vb, err := json.Marshal(v)
+		vb, err := json.Marshal(c.Extras[k])
 		if err != nil {
 			return err
 		}
@@ -102,17 +109,40 @@ func (c CloudEvent[A]) MarshalJSON() ([]byte, error) {
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
 
-	buf.WriteByte('{')
-	if err := c.marshalHeaderTo(buf); err != nil {
+	if err := c.writeJSONTo(buf); err != nil {
 		bufPool.Put(buf)
 		return nil, err
 	}
 
+	result := make([]byte, buf.Len())
+	copy(result, buf.Bytes())
+	bufPool.Put(buf)
+	return result, nil
+}
+
+// AppendJSON appends the JSON encoding of the event to dst and returns the extended buffer.
+// The output is identical to MarshalJSON, with extras in key order. Reusing dst across calls avoids allocating a new
+// slice per event. On error dst is returned unchanged.
+func (c CloudEvent[A]) AppendJSON(dst []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if err := c.writeJSONTo(buf); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONTo writes the full event JSON object to buf.
+func (c *CloudEvent[A]) writeJSONTo(buf *bytes.Buffer) error {
+	buf.WriteByte('{')
+	if err := c.marshalHeaderTo(buf); err != nil {
+		return err
+	}
+
 	if raw, ok := (any)(c.Data).(json.RawMessage); ok {
 		if len(raw) > 0 || c.DataBase64 != "" {
 			if c.DataBase64 != "" {
 				writeStringField(buf, "data_base64", c.DataBase64)
-			} else if IsJSONDataContentType(c.DataContentType) || (c.DataContentType == "" && json.Valid(raw)) {
+			} else if IsJSONContentType(c.DataContentType) || (c.DataContentType == "" && json.Valid(raw)) {
 				buf.WriteString(`,"data":`)
 				buf.Write(raw)
 			} else {
@@ -125,20 +155,20 @@ func (c CloudEvent[A]) MarshalJSON() ([]byte, error) {
 		} else {
 			dataBytes, err := json.Marshal(c.Data)
 			if err != nil {
-				bufPool.Put(buf)
-				return nil, err
+				return err
+			}
+			// A non-JSON content type marks the encoded bytes as opaque, as it does for a RawEvent.
+			if c.DataContentType != "" && !IsJSONContentType(c.DataContentType) {
+				writeStringField(buf, "data_base64", base64.StdEncoding.EncodeToString(dataBytes))
+			} else {
+				buf.WriteString(`,"data":`)
+				buf.Write(dataBytes)
 			}
-			buf.WriteString(`,"data":`)
-			buf.Write(dataBytes)
 		}
 	}
 
 	buf.WriteByte('}')
-
-	result := make([]byte, buf.Len())
-	copy(result, buf.Bytes())
-	bufPool.Put(buf)
-	return result, nil
+	return nil
 }
 
 // MarshalJSON implements custom JSON marshaling for CloudEventHeader.
@@ -158,3 +188,15 @@ func (c CloudEventHeader) MarshalJSON() ([]byte, error) {
 	bufPool.Put(buf)
 	return result, nil
 }
+
+// AppendJSON appends the JSON encoding of the header to dst and returns the extended buffer.
+// The output is identical to MarshalJSON, with extras in key order. On error dst is returned unchanged.
+func (c CloudEventHeader) AppendJSON(dst []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	buf.WriteByte('{')
+	if err := c.marshalHeaderTo(buf); err != nil {
+		return dst, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
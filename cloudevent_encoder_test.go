@@ -0,0 +1,137 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func benchmarkEvent() cloudevent.RawEvent {
+	return cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			SpecVersion:     cloudevent.SpecVersion,
+			Type:            cloudevent.TypeStatus,
+			Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+			Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+			ID:              "2pFfmfn3cNuDk3UVs4u6pPkMpFt",
+			Time:            time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC),
+			DataContentType: "application/json",
+			DataVersion:     "v2",
+			Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+			Signature:       "0xdeadbeef",
+			Tags:            []string{"a", "b"},
+			Extras:          map[string]any{"region": "eu"},
+		},
+		Data: json.RawMessage(`{"signals":[{"name":"speed","value":42.5,"timestamp":"2024-06-01T12:00:00Z"}]}`),
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	t.Parallel()
+
+	ev := benchmarkEvent()
+	want, err := json.Marshal(ev)
+	require.NoError(t, err)
+
+	prefix := []byte("prefix")
+	got, err := ev.AppendJSON(prefix)
+	require.NoError(t, err)
+	assert.Equal(t, "prefix"+string(want), string(got))
+
+	wantHdr, err := json.Marshal(ev.CloudEventHeader)
+	require.NoError(t, err)
+	gotHdr, err := ev.CloudEventHeader.AppendJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(wantHdr), string(gotHdr))
+
+	// Binary data is encoded as data_base64 in both paths.
+	ev.DataContentType = "application/octet-stream"
+	ev.Data = json.RawMessage{0xff, 0x00}
+	want, err = json.Marshal(ev)
+	require.NoError(t, err)
+	got, err = ev.AppendJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+
+	// Extras are written in key order, so repeated encodings are identical.
+	hdr := cloudevent.CloudEventHeader{ID: "1", Extras: map[string]any{"c": 3, "a": 1, "b": 2, "d": 4}}
+	first, err := hdr.AppendJSON(nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(first), `"a":1,"b":2,"c":3,"d":4`)
+	for range 20 {
+		again, err := hdr.MarshalJSON()
+		require.NoError(t, err)
+		require.Equal(t, string(first), string(again))
+	}
+
+	bad := cloudevent.CloudEventHeader{Extras: map[string]any{"bad": make(chan int)}}
+	got, err = bad.AppendJSON(prefix)
+	require.Error(t, err)
+	assert.Equal(t, prefix, got, "dst is returned unchanged on error")
+}
+
+func FuzzAppendJSON(f *testing.F) {
+	f.Add("type", "source", "subject", "id", int64(0), "application/json", "producer", "sig", "tag", "key", "value", []byte(`{"a":1}`))
+	f.Add("", "", "", "", int64(1717243200123456789), "", "", "", "", "", "", []byte{0xff})
+	f.Add("\"quoted\"", "back\\slash", "\n\t", " ", int64(-1), "text/plain", "ünïcode", "", "", "x", "\x00", []byte(nil))
+	f.Fuzz(func(t *testing.T, typ, source, subject, id string, nanos int64, contentType, producer, signature, tag, extraKey, extraValue string, data []byte) {
+		ev := cloudevent.RawEvent{
+			CloudEventHeader: cloudevent.CloudEventHeader{
+				Type:            typ,
+				Source:          source,
+				Subject:         subject,
+				ID:              id,
+				Time:            time.Unix(0, nanos).UTC(),
+				DataContentType: contentType,
+				Producer:        producer,
+				Signature:       signature,
+				Tags:            []string{tag},
+			},
+			Data: data,
+		}
+		// Several extras exercise their ordering.
+		if extraKey != "" {
+			ev.Extras = map[string]any{extraKey: extraValue, extraKey + "2": extraValue, "a" + extraKey: nanos}
+		}
+
+		want, wantErr := ev.MarshalJSON()
+		got, gotErr := ev.AppendJSON(nil)
+		require.Equal(t, wantErr == nil, gotErr == nil)
+		require.Equal(t, string(want), string(got))
+
+		want, wantErr = ev.CloudEventHeader.MarshalJSON()
+		got, gotErr = ev.CloudEventHeader.AppendJSON(nil)
+		require.Equal(t, wantErr == nil, gotErr == nil)
+		require.Equal(t, string(want), string(got))
+	})
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	ev := benchmarkEvent()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ev.MarshalJSON(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendJSON_Pooled(b *testing.B) {
+	ev := benchmarkEvent()
+	pool := sync.Pool{New: func() any { return new([]byte) }}
+	b.ReportAllocs()
+	for b.Loop() {
+		bp := pool.Get().(*[]byte)
+		out, err := ev.AppendJSON((*bp)[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+		*bp = out
+		pool.Put(bp)
+	}
+}
@@ -461,3 +461,130 @@ func TestCloudEventHeader_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestNewCloudEventHeader(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	h, err := cloudevent.NewCloudEventHeader("0xsource", "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1", cloudevent.TypeStatus,
+		cloudevent.WithProducer("did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2"),
+		cloudevent.WithDataVersion("v2"),
+		cloudevent.WithDataContentType(cloudevent.ContentTypeJSON),
+		cloudevent.WithExtra("region", "eu"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, h.Validate())
+	assert.Equal(t, cloudevent.SpecVersion, h.SpecVersion)
+	assert.NotEmpty(t, h.ID)
+	assert.Equal(t, time.UTC, h.Time.Location())
+	assert.False(t, h.Time.Before(before.Truncate(time.Second)))
+	assert.Equal(t, "v2", h.DataVersion)
+	assert.Equal(t, cloudevent.ContentTypeJSON, h.DataContentType)
+	assert.Equal(t, map[string]any{"region": "eu"}, h.Extras)
+
+	other, err := cloudevent.NewCloudEventHeader("0xsource", "did:x:y", cloudevent.TypeStatus)
+	require.NoError(t, err)
+	assert.NotEqual(t, h.ID, other.ID)
+
+	_, err = cloudevent.NewCloudEventHeader("0xsource", "did:x:y", cloudevent.TypeStatus, cloudevent.WithExtra("specversion", "2.0"))
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	subject := "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1"
+	ev, err := cloudevent.New(TestData{Message: "hi", Count: 1},
+		cloudevent.WithSource("0xsource"),
+		cloudevent.WithSubject(subject),
+		cloudevent.WithType(cloudevent.TypeStatus),
+		cloudevent.WithProducer(subject),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, TestData{Message: "hi", Count: 1}, ev.Data)
+	assert.Equal(t, cloudevent.SpecVersion, ev.SpecVersion)
+	assert.NotEmpty(t, ev.ID)
+	assert.False(t, ev.Time.IsZero())
+	assert.Equal(t, subject, ev.Producer)
+
+	fixed := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	ev, err = cloudevent.New(TestData{},
+		cloudevent.WithSource("0xsource"),
+		cloudevent.WithSubject(subject),
+		cloudevent.WithType(cloudevent.TypeStatus),
+		cloudevent.WithID("fixed"),
+		cloudevent.WithTime(fixed),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", ev.ID)
+	assert.Equal(t, fixed, ev.Time)
+
+	_, err = cloudevent.New(TestData{}, cloudevent.WithSource("0xsource"), cloudevent.WithType(cloudevent.TypeStatus))
+	require.ErrorContains(t, err, "missing_subject")
+
+	_, err = cloudevent.New(TestData{},
+		cloudevent.WithSource("0xsource"),
+		cloudevent.WithSubject(subject),
+		cloudevent.WithType(cloudevent.TypeStatus),
+		cloudevent.WithExtra("data", "x"),
+	)
+	require.ErrorContains(t, err, `"data"`)
+
+	assert.Panics(t, func() { cloudevent.MustNew(TestData{}) })
+	assert.NotPanics(t, func() {
+		cloudevent.MustNew(TestData{}, cloudevent.WithSource("s"), cloudevent.WithSubject(subject), cloudevent.WithType(cloudevent.TypeStatus))
+	})
+}
+
+func TestCloudEventHeader_Key(t *testing.T) {
+	t.Parallel()
+
+	h := cloudevent.CloudEventHeader{
+		Subject: "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+		Time:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Type:    cloudevent.TypeStatus,
+		Source:  "0xsource",
+		ID:      "1",
+	}
+	want := "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1!2024-06-01T12:00:00Z!dimo.status!0xsource!1"
+	assert.Equal(t, want, h.Key())
+	assert.Equal(t, h.Key(), h.LegacyKey(), "whole-second times produce the same key in both formats")
+
+	later := h
+	later.Time = h.Time.Add(250 * time.Millisecond)
+	assert.Equal(t, "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1!2024-06-01T12:00:00.25Z!dimo.status!0xsource!1", later.Key())
+	assert.NotEqual(t, h.Key(), later.Key())
+	assert.Equal(t, h.LegacyKey(), later.LegacyKey(), "legacy keys collide on sub-second times")
+	assert.False(t, h.Equals(later))
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	h := cloudevent.CloudEventHeader{
+		Subject: "did:erc721:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:123",
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 123000000, time.UTC),
+		Type:    cloudevent.TypeStatus,
+		Source:  "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		ID:      "2pFfmfn3cNuDk3UVs4u6pPkMpFt",
+	}
+	want := cloudevent.KeyParts{Subject: h.Subject, Time: h.Time, Type: h.Type, Source: h.Source, ID: h.ID}
+
+	got, err := cloudevent.ParseKey(h.Key())
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = cloudevent.ParseKey(h.LegacyKey())
+	require.NoError(t, err)
+	assert.Equal(t, h.Time.Truncate(time.Second), got.Time)
+
+	h.ID = "id!with!bangs"
+	got, err = cloudevent.ParseKey(h.Key())
+	require.NoError(t, err)
+	assert.Equal(t, h.ID, got.ID)
+
+	_, err = cloudevent.ParseKey("a!b!c")
+	require.Error(t, err)
+	_, err = cloudevent.ParseKey("s!yesterday!t!src!id")
+	require.Error(t, err)
+}
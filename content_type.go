@@ -0,0 +1,51 @@
+package cloudevent
+
+import (
+	"mime"
+	"strings"
+)
+
+// Common values for DataContentType.
+const (
+	// ContentTypeJSON is the content type of JSON data.
+	ContentTypeJSON = "application/json"
+	// ContentTypeCloudEventsJSON is the content type of a structured-mode CloudEvent in JSON.
+	ContentTypeCloudEventsJSON = "application/cloudevents+json"
+	// ContentTypeOctetStream is the content type of opaque binary data.
+	ContentTypeOctetStream = "application/octet-stream"
+	// ContentTypeGzip is the content type of gzip-compressed data.
+	ContentTypeGzip = "application/gzip"
+	// ContentTypeGzipJSON is the content type of gzip-compressed JSON data. The payload is not
+	// JSON until it is decompressed, so IsJSONContentType reports false for it.
+	ContentTypeGzipJSON = "application/json+gzip"
+	// ContentTypeText is the content type of plain text data.
+	ContentTypeText = "text/plain"
+)
+
+// NormalizeContentType returns the lower-case "type/subtype" of a MIME type with its
+// parameters removed, e.g. "Application/JSON; charset=utf-8" becomes "application/json".
+// The bare alias "json" maps to ContentTypeJSON. Values that are not a valid
+// "type/subtype", such as "utf-8", normalize to "".
+func NormalizeContentType(ct string) string {
+	ct = strings.TrimSpace(ct)
+	if strings.EqualFold(ct, "json") {
+		return ContentTypeJSON
+	}
+	parsed, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ""
+	}
+	typ, subtype, ok := strings.Cut(parsed, "/")
+	if !ok || typ == "" || subtype == "" {
+		return ""
+	}
+	return parsed
+}
+
+// IsJSONContentType reports whether the MIME type indicates a JSON payload: "application/json"
+// or any "+json" suffix type (e.g. "application/cloudevents+json"), after normalizing with
+// NormalizeContentType.
+func IsJSONContentType(ct string) bool {
+	parsed := NormalizeContentType(ct)
+	return parsed == ContentTypeJSON || strings.HasSuffix(parsed, "+json")
+}
@@ -0,0 +1,41 @@
+package cloudevent_test
+
+import (
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+		json  bool
+	}{
+		{input: "application/json", want: cloudevent.ContentTypeJSON, json: true},
+		{input: "application/json; charset=utf-8", want: cloudevent.ContentTypeJSON, json: true},
+		{input: "  Application/JSON;charset=UTF-8 ", want: cloudevent.ContentTypeJSON, json: true},
+		{input: "json", want: cloudevent.ContentTypeJSON, json: true},
+		{input: "JSON", want: cloudevent.ContentTypeJSON, json: true},
+		{input: "application/cloudevents+json", want: cloudevent.ContentTypeCloudEventsJSON, json: true},
+		{input: "application/cloudevents+json; charset=utf-8", want: cloudevent.ContentTypeCloudEventsJSON, json: true},
+		{input: "application/vnd.dimo.status+json", want: "application/vnd.dimo.status+json", json: true},
+		{input: "application/octet-stream", want: cloudevent.ContentTypeOctetStream},
+		{input: "application/gzip", want: cloudevent.ContentTypeGzip},
+		{input: "application/json+gzip", want: cloudevent.ContentTypeGzipJSON},
+		{input: "text/plain; charset=us-ascii", want: cloudevent.ContentTypeText},
+		{input: "image/jpeg", want: "image/jpeg"},
+		{input: "utf-8", want: ""},
+		{input: "application/", want: ""},
+		{input: ";charset=utf-8", want: ""},
+		{input: "", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, cloudevent.NormalizeContentType(tt.input), tt.input)
+		assert.Equal(t, tt.json, cloudevent.IsJSONContentType(tt.input), tt.input)
+		assert.Equal(t, tt.json, cloudevent.IsJSONDataContentType(tt.input), tt.input)
+	}
+}
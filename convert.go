@@ -0,0 +1,78 @@
+package cloudevent
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// ConvertData decodes the data of ev into an A and returns a typed event with the same header,
+// including Extras, and DataBase64. An event without data yields the zero A. As when decoding a
+// typed event, ValidateEventData is called if A implements EventDataValidator. Errors wrap
+// ErrMalformedData and name the event ID.
+func ConvertData[A any](ev RawEvent) (CloudEvent[A], error) {
+	out := CloudEvent[A]{CloudEventHeader: ev.CloudEventHeader, DataBase64: ev.DataBase64}
+	if len(ev.Data) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(ev.Data, &out.Data); err != nil {
+		return out, fmt.Errorf("%w: event %q: %w", ErrMalformedData, ev.ID, err)
+	}
+	if err := validateEventData(&out.Data, ev.ID); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// ToRaw encodes the data of ev as JSON and returns a RawEvent with the same header. Only Data is
+// marshaled, not the envelope. When DataBase64 is set it is what the event carries on the wire,
+// so Data holds its decoded bytes instead, as it would after decoding. A nil pointer, map or
+// slice becomes the JSON literal null.
+func ToRaw[A any](ev CloudEvent[A]) (RawEvent, error) {
+	out := RawEvent{CloudEventHeader: ev.CloudEventHeader, DataBase64: ev.DataBase64}
+	if raw, ok := any(ev.Data).(json.RawMessage); ok && ev.DataBase64 == "" {
+		out.Data = raw
+		return out, nil
+	}
+	if ev.DataBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(ev.DataBase64)
+		if err != nil {
+			return RawEvent{}, fmt.Errorf("%w: event %q: invalid data_base64: %w", ErrMalformedData, ev.ID, err)
+		}
+		out.Data = decoded
+		return out, nil
+	}
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return RawEvent{}, fmt.Errorf("%w: event %q: %w", ErrMalformedData, ev.ID, err)
+	}
+	out.Data = data
+	return out, nil
+}
+
+// AsRawEvent is ToRaw under the name used by RawEvent-only writers such as the event repo and
+// Kafka producers. Marshaling the result produces the same bytes as marshaling ev.
+func AsRawEvent[A any](ev CloudEvent[A]) (RawEvent, error) {
+	return ToRaw(ev)
+}
+
+// DataAs decodes the event data as JSON into v, which must be a non-nil pointer, mirroring the
+// CloudEvents SDK's DataAs. It fails if DataContentType is set to a non-JSON type or the event has
+// no data, and calls ValidateEventData if v implements EventDataValidator. Errors name the event
+// ID and content type; decoding failures wrap ErrMalformedData.
+func (c CloudEvent[A]) DataAs(v any) error {
+	if c.DataContentType != "" && !IsJSONContentType(c.DataContentType) {
+		return fmt.Errorf("cloudevent: event %q: cannot decode data with datacontenttype %q as JSON", c.ID, c.DataContentType)
+	}
+	raw, err := ToRaw(c)
+	if err != nil {
+		return err
+	}
+	if len(raw.Data) == 0 {
+		return fmt.Errorf("cloudevent: event %q has no data", c.ID)
+	}
+	if err := json.Unmarshal(raw.Data, v); err != nil {
+		return fmt.Errorf("%w: event %q (datacontenttype %q): %w", ErrMalformedData, c.ID, c.DataContentType, err)
+	}
+	return validateEventData(v, c.ID)
+}
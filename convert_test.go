@@ -0,0 +1,148 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertData(t *testing.T) {
+	t.Parallel()
+
+	raw := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "ev-1", Type: cloudevent.TypeStatus, Extras: map[string]any{"region": "eu"}},
+		Data:             json.RawMessage(`{"message":"hi","count":2}`),
+	}
+	typed, err := cloudevent.ConvertData[TestData](raw)
+	require.NoError(t, err)
+	assert.Equal(t, raw.CloudEventHeader, typed.CloudEventHeader)
+	assert.Equal(t, TestData{Message: "hi", Count: 2}, typed.Data)
+
+	back, err := cloudevent.ToRaw(typed)
+	require.NoError(t, err)
+	assert.Equal(t, raw.CloudEventHeader, back.CloudEventHeader)
+	assert.JSONEq(t, string(raw.Data), string(back.Data))
+
+	raw.Data = json.RawMessage(`{"count":"two"}`)
+	_, err = cloudevent.ConvertData[TestData](raw)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	assert.ErrorContains(t, err, `"ev-1"`)
+
+	raw.Data = nil
+	typed, err = cloudevent.ConvertData[TestData](raw)
+	require.NoError(t, err)
+	assert.Zero(t, typed.Data)
+}
+
+func TestConvertData_Validates(t *testing.T) {
+	t.Parallel()
+
+	raw := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "ev-1"},
+		Data:             json.RawMessage(`{"odometer":-1}`),
+	}
+	_, err := cloudevent.ConvertData[odometerData](raw)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+}
+
+func TestToRaw(t *testing.T) {
+	t.Parallel()
+
+	nilData := cloudevent.CloudEvent[*TestData]{CloudEventHeader: cloudevent.CloudEventHeader{ID: "1"}}
+	raw, err := cloudevent.ToRaw(nilData)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(raw.Data))
+
+	b64 := cloudevent.CloudEvent[TestData]{CloudEventHeader: cloudevent.CloudEventHeader{ID: "1"}, DataBase64: "eyJtZXNzYWdlIjoiaGkifQ=="}
+	raw, err = cloudevent.ToRaw(b64)
+	require.NoError(t, err)
+	assert.Equal(t, `{"message":"hi"}`, string(raw.Data))
+	assert.Equal(t, b64.DataBase64, raw.DataBase64)
+
+	b64.DataBase64 = "not base64!"
+	_, err = cloudevent.ToRaw(b64)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+
+	unencodable := cloudevent.CloudEvent[chan int]{CloudEventHeader: cloudevent.CloudEventHeader{ID: "bad"}, Data: make(chan int)}
+	_, err = cloudevent.ToRaw(unencodable)
+	require.ErrorContains(t, err, `"bad"`)
+}
+
+func TestCloudEvent_DataAs(t *testing.T) {
+	t.Parallel()
+
+	ev := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "ev-1", DataContentType: "application/json; charset=utf-8"},
+		Data:             json.RawMessage(`{"message":"hi","count":2}`),
+	}
+	var got TestData
+	require.NoError(t, ev.DataAs(&got))
+	assert.Equal(t, TestData{Message: "hi", Count: 2}, got)
+
+	ev.DataContentType = ""
+	got = TestData{}
+	require.NoError(t, ev.DataAs(&got), "an unset content type is JSON")
+	assert.Equal(t, "hi", got.Message)
+
+	err := ev.DataAs(got)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData, "non-pointer targets fail")
+
+	ev.DataContentType = cloudevent.ContentTypeOctetStream
+	err = ev.DataAs(&got)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `"ev-1"`)
+	assert.ErrorContains(t, err, `"application/octet-stream"`)
+
+	ev.DataContentType = cloudevent.ContentTypeJSON
+	ev.Data = json.RawMessage(`{"odometer":-1}`)
+	var odo odometerData
+	require.ErrorIs(t, ev.DataAs(&odo), cloudevent.ErrMalformedData)
+
+	ev.Data = nil
+	require.ErrorContains(t, ev.DataAs(&got), "has no data")
+
+	typed := cloudevent.CloudEvent[TestData]{Data: TestData{Message: "typed"}}
+	var generic map[string]any
+	require.NoError(t, typed.DataAs(&generic))
+	assert.Equal(t, "typed", generic["message"])
+}
+
+func TestToRaw_WireEquivalence(t *testing.T) {
+	t.Parallel()
+
+	header := cloudevent.CloudEventHeader{
+		SpecVersion: cloudevent.SpecVersion,
+		ID:          "1",
+		Type:        cloudevent.TypeStatus,
+		Extras:      map[string]any{"region": "eu"},
+	}
+	withContentType := header
+	withContentType.DataContentType = cloudevent.ContentTypeJSON
+	opaque := header
+	opaque.DataContentType = cloudevent.ContentTypeOctetStream
+
+	for name, ev := range map[string]cloudevent.CloudEvent[*TestData]{
+		"value":        {CloudEventHeader: header, Data: &TestData{Message: "hi", Count: 1}},
+		"content type": {CloudEventHeader: withContentType, Data: &TestData{Message: "hi"}},
+		"non-JSON":     {CloudEventHeader: opaque, Data: &TestData{Message: "hi"}},
+		"nil data":     {CloudEventHeader: header},
+		"data_base64":  {CloudEventHeader: header, DataBase64: "eyJtZXNzYWdlIjoiaGkifQ=="},
+	} {
+		want, err := json.Marshal(ev)
+		require.NoError(t, err, name)
+		raw, err := cloudevent.ToRaw(ev)
+		require.NoError(t, err, name)
+		got, err := json.Marshal(raw)
+		require.NoError(t, err, name)
+		assert.Equal(t, string(want), string(got), name)
+
+		raw, err = cloudevent.AsRawEvent(ev)
+		require.NoError(t, err, name)
+		got, err = json.Marshal(raw)
+		require.NoError(t, err, name)
+		assert.Equal(t, string(want), string(got), name)
+	}
+}
@@ -1,6 +1,8 @@
 package cloudevent
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -21,6 +23,21 @@ const (
 
 var errInvalidDID = errors.New("invalid DID")
 
+// DID is implemented by the DID types in this package.
+type DID interface {
+	fmt.Stringer
+	// Validate returns an error if the DID cannot be encoded as a valid DID string.
+	Validate() error
+	// IsZero reports whether the DID is the zero value.
+	IsZero() bool
+}
+
+var (
+	_ DID = ERC721DID{}
+	_ DID = EthrDID{}
+	_ DID = ERC20DID{}
+)
+
 // ERC721DID is a Decentralized Identifier for a ERC721 NFT.
 type ERC721DID struct {
 	ChainID         uint64         `json:"chainId"`
@@ -69,6 +86,25 @@ func (e ERC721DID) String() string {
 	return "did:" + ERC721DIDMethod + ":" + strconv.FormatUint(e.ChainID, 10) + ":" + e.ContractAddress.Hex() + ":" + e.TokenID.String()
 }
 
+// Validate returns an error if the contract address is zero or the token ID is missing or negative.
+func (e ERC721DID) Validate() error {
+	if e.ContractAddress == (common.Address{}) {
+		return fmt.Errorf("%w, contract address is zero", errInvalidDID)
+	}
+	if e.TokenID == nil {
+		return fmt.Errorf("%w, token ID is missing", errInvalidDID)
+	}
+	if e.TokenID.Sign() < 0 {
+		return fmt.Errorf("%w, token ID cannot be negative %s", errInvalidDID, e.TokenID)
+	}
+	return nil
+}
+
+// IsZero reports whether the ERC721DID is the zero value.
+func (e ERC721DID) IsZero() bool {
+	return e.ChainID == 0 && e.ContractAddress == (common.Address{}) && e.TokenID == nil
+}
+
 // MarshalText implements encoding.TextMarshaler
 func (e ERC721DID) MarshalText() ([]byte, error) {
 	return []byte(e.String()), nil
@@ -84,6 +120,33 @@ func (e *ERC721DID) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// UnmarshalJSON accepts both the DID string, including the legacy did:nft form, and the
+// object form {"chainId":1,"contract":"0x...","tokenId":1} written by older producers.
+// Token IDs in the object form may be numbers of any size or decimal strings.
+func (e *ERC721DID) UnmarshalJSON(data []byte) error {
+	obj, isObject, err := unmarshalDIDJSON(data, func(s string) error {
+		did, err := DecodeERC721orNFTDID(s)
+		if err != nil {
+			return err
+		}
+		*e = did
+		return nil
+	})
+	if err != nil || !isObject {
+		return err
+	}
+	did := ERC721DID{ChainID: obj.chainID, ContractAddress: obj.contract}
+	if obj.tokenID != nil {
+		tokenID, ok := new(big.Int).SetString(strings.Trim(string(obj.tokenID), `"`), 10)
+		if !ok || tokenID.Sign() < 0 {
+			return fmt.Errorf("%w, invalid token ID %s", errInvalidDID, obj.tokenID)
+		}
+		did.TokenID = tokenID
+	}
+	*e = did
+	return nil
+}
+
 // EthrDID is a Decentralized Identifier for an Ethereum contract.
 type EthrDID struct {
 	ChainID         uint64         `json:"chainId"`
@@ -107,6 +170,16 @@ func (e EthrDID) String() string {
 	return encodeAddressDID(EthrDIDMethod, e.ChainID, e.ContractAddress)
 }
 
+// Validate returns an error if the contract address is zero.
+func (e EthrDID) Validate() error {
+	return validateAddressDID(e.ContractAddress)
+}
+
+// IsZero reports whether the EthrDID is the zero value.
+func (e EthrDID) IsZero() bool {
+	return e.ChainID == 0 && e.ContractAddress == (common.Address{})
+}
+
 // MarshalText implements encoding.TextMarshaler
 func (e EthrDID) MarshalText() ([]byte, error) {
 	return []byte(e.String()), nil
@@ -122,6 +195,18 @@ func (e *EthrDID) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// UnmarshalJSON accepts both the DID string and the object form {"chainId":1,"contract":"0x..."}.
+func (e *EthrDID) UnmarshalJSON(data []byte) error {
+	obj, isObject, err := unmarshalDIDJSON(data, func(s string) error {
+		return e.UnmarshalText([]byte(s))
+	})
+	if err != nil || !isObject {
+		return err
+	}
+	*e = EthrDID{ChainID: obj.chainID, ContractAddress: obj.contract}
+	return nil
+}
+
 // ERC20DID is a Decentralized Identifier for an ERC20 token.
 type ERC20DID struct {
 	ChainID         uint64         `json:"chainId"`
@@ -145,6 +230,16 @@ func (e ERC20DID) String() string {
 	return encodeAddressDID(ERC20DIDMethod, e.ChainID, e.ContractAddress)
 }
 
+// Validate returns an error if the contract address is zero.
+func (e ERC20DID) Validate() error {
+	return validateAddressDID(e.ContractAddress)
+}
+
+// IsZero reports whether the ERC20DID is the zero value.
+func (e ERC20DID) IsZero() bool {
+	return e.ChainID == 0 && e.ContractAddress == (common.Address{})
+}
+
 // MarshalText implements encoding.TextMarshaler
 func (e ERC20DID) MarshalText() ([]byte, error) {
 	return []byte(e.String()), nil
@@ -160,6 +255,76 @@ func (e *ERC20DID) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// UnmarshalJSON accepts both the DID string and the object form {"chainId":1,"contract":"0x..."}.
+func (e *ERC20DID) UnmarshalJSON(data []byte) error {
+	obj, isObject, err := unmarshalDIDJSON(data, func(s string) error {
+		return e.UnmarshalText([]byte(s))
+	})
+	if err != nil || !isObject {
+		return err
+	}
+	*e = ERC20DID{ChainID: obj.chainID, ContractAddress: obj.contract}
+	return nil
+}
+
+// didObject holds the fields of the object form of a DID.
+type didObject struct {
+	chainID  uint64
+	contract common.Address
+	tokenID  json.RawMessage
+}
+
+// unmarshalDIDJSON decodes a DID encoded as a JSON string, by passing it to decodeString, or as
+// an object. It reports whether data was an object; null leaves the DID unchanged.
+func unmarshalDIDJSON(data []byte, decodeString func(string) error) (didObject, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return didObject{}, false, fmt.Errorf("%w, empty JSON", errInvalidDID)
+	}
+	switch data[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return didObject{}, false, err
+		}
+		return didObject{}, false, decodeString(s)
+	case '{':
+		var raw struct {
+			ChainID  json.Number     `json:"chainId"`
+			Contract string          `json:"contract"`
+			TokenID  json.RawMessage `json:"tokenId"`
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return didObject{}, false, fmt.Errorf("%w, %w", errInvalidDID, err)
+		}
+		var obj didObject
+		if raw.ChainID != "" {
+			chainID, err := strconv.ParseUint(raw.ChainID.String(), 10, 64)
+			if err != nil {
+				return didObject{}, false, fmt.Errorf("%w, invalid chain ID %s", errInvalidDID, raw.ChainID)
+			}
+			obj.chainID = chainID
+		}
+		if raw.Contract != "" {
+			if !common.IsHexAddress(raw.Contract) {
+				return didObject{}, false, fmt.Errorf("%w, invalid contract address %s", errInvalidDID, raw.Contract)
+			}
+			obj.contract = common.HexToAddress(raw.Contract)
+		}
+		if len(raw.TokenID) > 0 && string(raw.TokenID) != "null" {
+			obj.tokenID = raw.TokenID
+		}
+		return obj, true, nil
+	case 'n':
+		if string(data) == "null" {
+			return didObject{}, false, nil
+		}
+	}
+	return didObject{}, false, fmt.Errorf("%w, expected a string or an object", errInvalidDID)
+}
+
 func decodeAddressDID(did string, method string) (uint64, common.Address, error) {
 	// sample did "did:method:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF"
 	parts := strings.Split(did, ":")
@@ -184,6 +349,13 @@ func decodeAddressDID(did string, method string) (uint64, common.Address, error)
 	return chainID, common.HexToAddress(addrBytes), nil
 }
 
+func validateAddressDID(contractAddress common.Address) error {
+	if contractAddress == (common.Address{}) {
+		return fmt.Errorf("%w, contract address is zero", errInvalidDID)
+	}
+	return nil
+}
+
 func encodeAddressDID(method string, chainID uint64, contractAddress common.Address) string {
 	return "did:" + method + ":" + strconv.FormatUint(chainID, 10) + ":" + contractAddress.Hex()
 }
@@ -242,3 +414,33 @@ func DecodeLegacyNFTDID(did string) (ERC721DID, error) {
 func EncodeLegacyNFTDID(chainID uint64, contractAddress common.Address, tokenID *big.Int) string {
 	return "did:nft:" + strconv.FormatUint(chainID, 10) + ":" + contractAddress.Hex() + "_" + tokenID.String()
 }
+
+// SetSubject validates d and sets Subject to its string form.
+func (c *CloudEventHeader) SetSubject(d DID) error {
+	s, err := didString(d)
+	if err != nil {
+		return fmt.Errorf("invalid subject: %w", err)
+	}
+	c.Subject = s
+	return nil
+}
+
+// SetProducer validates d and sets Producer to its string form.
+func (c *CloudEventHeader) SetProducer(d DID) error {
+	s, err := didString(d)
+	if err != nil {
+		return fmt.Errorf("invalid producer: %w", err)
+	}
+	c.Producer = s
+	return nil
+}
+
+func didString(d DID) (string, error) {
+	if d == nil || d.IsZero() {
+		return "", fmt.Errorf("%w, DID is empty", errInvalidDID)
+	}
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
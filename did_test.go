@@ -1,6 +1,7 @@
 package cloudevent_test
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -577,3 +578,150 @@ func TestERC20DID_MarshalUnmarshalText(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestDID_Validate(t *testing.T) {
+	contract := common.HexToAddress("0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF")
+	tests := []struct {
+		name    string
+		did     cloudevent.DID
+		zero    bool
+		wantErr bool
+	}{
+		{name: "valid ERC721", did: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: big.NewInt(0)}},
+		{name: "ERC721 nil token ID", did: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract}, wantErr: true},
+		{name: "ERC721 negative token ID", did: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: big.NewInt(-1)}, wantErr: true},
+		{name: "ERC721 zero address", did: cloudevent.ERC721DID{ChainID: 1, TokenID: big.NewInt(1)}, wantErr: true},
+		{name: "zero ERC721", did: cloudevent.ERC721DID{}, zero: true, wantErr: true},
+		{name: "valid Ethr", did: cloudevent.EthrDID{ChainID: 1, ContractAddress: contract}},
+		{name: "zero Ethr", did: cloudevent.EthrDID{}, zero: true, wantErr: true},
+		{name: "valid ERC20", did: cloudevent.ERC20DID{ChainID: 1, ContractAddress: contract}},
+		{name: "ERC20 zero address", did: cloudevent.ERC20DID{ChainID: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.zero, tt.did.IsZero())
+			if tt.wantErr {
+				require.Error(t, tt.did.Validate())
+			} else {
+				require.NoError(t, tt.did.Validate())
+			}
+		})
+	}
+}
+
+func TestCloudEventHeader_SetSubjectAndProducer(t *testing.T) {
+	contract := common.HexToAddress("0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF")
+	var hdr cloudevent.CloudEventHeader
+
+	require.NoError(t, hdr.SetSubject(cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: big.NewInt(7)}))
+	require.Equal(t, "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:7", hdr.Subject)
+	require.NoError(t, hdr.SetProducer(cloudevent.EthrDID{ChainID: 1, ContractAddress: contract}))
+	require.Equal(t, "did:ethr:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF", hdr.Producer)
+
+	// Invalid DIDs are rejected at set time and leave the header unchanged.
+	require.Error(t, hdr.SetSubject(cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract}))
+	require.Error(t, hdr.SetSubject(nil))
+	require.Error(t, hdr.SetProducer(cloudevent.ERC721DID{}))
+	require.Equal(t, "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:7", hdr.Subject)
+	require.Equal(t, "did:ethr:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF", hdr.Producer)
+}
+
+func TestNewCloudEventHeader_DIDOptions(t *testing.T) {
+	contract := common.HexToAddress("0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF")
+
+	hdr, err := cloudevent.NewCloudEventHeader("0xsource", "", cloudevent.TypeStatus,
+		cloudevent.WithSubjectDID(cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: big.NewInt(7)}),
+		cloudevent.WithProducerDID(cloudevent.EthrDID{ChainID: 1, ContractAddress: contract}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:7", hdr.Subject)
+	require.Equal(t, "did:ethr:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF", hdr.Producer)
+
+	// A DID that fails validation fails the build.
+	_, err = cloudevent.NewCloudEventHeader("0xsource", "", cloudevent.TypeStatus,
+		cloudevent.WithSubjectDID(cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract}))
+	require.ErrorContains(t, err, "invalid subject")
+	_, err = cloudevent.NewCloudEventHeader("0xsource", "", cloudevent.TypeStatus,
+		cloudevent.WithProducerDID(nil))
+	require.ErrorContains(t, err, "invalid producer")
+}
+
+func TestDID_UnmarshalJSON(t *testing.T) {
+	type vehicleData struct {
+		Vehicle cloudevent.ERC721DID `json:"vehicle"`
+		Owner   cloudevent.EthrDID   `json:"owner"`
+		Token   cloudevent.ERC20DID  `json:"token"`
+	}
+	contract := common.HexToAddress("0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF")
+	hugeTokenID, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+
+	tests := []struct {
+		name    string
+		data    string
+		want    vehicleData
+		wantErr bool
+	}{
+		{
+			name: "string form",
+			data: `{"vehicle":"did:erc721:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:123","owner":"did:ethr:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF","token":"did:erc20:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF"}`,
+			want: vehicleData{
+				Vehicle: cloudevent.ERC721DID{ChainID: 137, ContractAddress: contract, TokenID: big.NewInt(123)},
+				Owner:   cloudevent.EthrDID{ChainID: 137, ContractAddress: contract},
+				Token:   cloudevent.ERC20DID{ChainID: 137, ContractAddress: contract},
+			},
+		},
+		{
+			name: "legacy nft string",
+			data: `{"vehicle":"did:nft:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF_123"}`,
+			want: vehicleData{Vehicle: cloudevent.ERC721DID{ChainID: 137, ContractAddress: contract, TokenID: big.NewInt(123)}},
+		},
+		{
+			name: "object form",
+			data: `{"vehicle":{"chainId":137,"contract":"0xba5738a18d83d41847dffbdc6101d37c69c9b0cf","tokenId":123},"owner":{"chainId":137,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF"},"token":{"chainId":137,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF"}}`,
+			want: vehicleData{
+				Vehicle: cloudevent.ERC721DID{ChainID: 137, ContractAddress: contract, TokenID: big.NewInt(123)},
+				Owner:   cloudevent.EthrDID{ChainID: 137, ContractAddress: contract},
+				Token:   cloudevent.ERC20DID{ChainID: 137, ContractAddress: contract},
+			},
+		},
+		{
+			name: "object form with huge token ID",
+			data: `{"vehicle":{"chainId":1,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF","tokenId":115792089237316195423570985008687907853269984665640564039457584007913129639935}}`,
+			want: vehicleData{Vehicle: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: hugeTokenID}},
+		},
+		{
+			name: "object form with string token ID",
+			data: `{"vehicle":{"chainId":1,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF","tokenId":"7"}}`,
+			want: vehicleData{Vehicle: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: big.NewInt(7)}},
+		},
+		{
+			name: "null",
+			data: `{"vehicle":null}`,
+		},
+		{name: "invalid string", data: `{"vehicle":"did:erc721:1"}`, wantErr: true},
+		{name: "invalid token ID", data: `{"vehicle":{"chainId":1,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF","tokenId":1.5}}`, wantErr: true},
+		{name: "negative token ID", data: `{"vehicle":{"chainId":1,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF","tokenId":-1}}`, wantErr: true},
+		{name: "invalid contract", data: `{"owner":{"chainId":1,"contract":"0x12"}}`, wantErr: true},
+		{name: "invalid chain ID", data: `{"token":{"chainId":-1,"contract":"0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF"}}`, wantErr: true},
+		{name: "number", data: `{"vehicle":1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ev cloudevent.CloudEvent[vehicleData]
+			err := json.Unmarshal([]byte(`{"id":"1","data":`+tt.data+`}`), &ev)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ev.Data)
+		})
+	}
+
+	// Marshaling emits the string form.
+	encoded, err := json.Marshal(vehicleData{Vehicle: cloudevent.ERC721DID{ChainID: 1, ContractAddress: contract, TokenID: hugeTokenID}})
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"vehicle":"did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:`+hugeTokenID.String()+`"`)
+}
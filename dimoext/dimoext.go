@@ -0,0 +1,170 @@
+// Package dimoext provides typed access to the DIMO extension attributes carried in
+// cloudevent.CloudEventHeader.Extras.
+//
+// Each extension has a canonical key, following the CloudEvents rule that extension names
+// are lower-case alphanumerics, and a list of legacy spellings that producers have used.
+// Getters accept either; setters and NormalizeDIMOExtensions write only the canonical key.
+package dimoext
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/DIMO-Network/cloudevent"
+)
+
+const (
+	// VehicleTokenIDKey is the canonical key of the vehicle NFT token ID.
+	VehicleTokenIDKey = "vehicletokenid"
+	// IntegrationIDKey is the canonical key of the integration ID.
+	IntegrationIDKey = "integrationid"
+	// DeviceDefinitionIDKey is the canonical key of the device definition ID.
+	DeviceDefinitionIDKey = "devicedefinitionid"
+	// RegionCodeKey is the canonical key of the region code.
+	RegionCodeKey = "regioncode"
+)
+
+// legacyKeys maps each canonical key to the other spellings seen in production, in lookup order.
+var legacyKeys = map[string][]string{
+	VehicleTokenIDKey:     {"vehicleTokenId", "vehicleTokenID", "vehicle_token_id", "VehicleTokenID"},
+	IntegrationIDKey:      {"integrationId", "integrationID", "integration_id", "IntegrationID"},
+	DeviceDefinitionIDKey: {"deviceDefinitionId", "deviceDefinitionID", "device_definition_id", "DeviceDefinitionID"},
+	RegionCodeKey:         {"regionCode", "region_code", "RegionCode"},
+}
+
+// CanonicalKeys returns the canonical extension keys, e.g. for filtering on extras.
+func CanonicalKeys() []string {
+	return []string{VehicleTokenIDKey, IntegrationIDKey, DeviceDefinitionIDKey, RegionCodeKey}
+}
+
+// LegacyKeys returns the legacy spellings of a canonical key.
+func LegacyKeys(canonical string) []string {
+	return append([]string(nil), legacyKeys[canonical]...)
+}
+
+// lookup returns the value under the canonical key or, failing that, the first legacy spelling present.
+func lookup(h cloudevent.CloudEventHeader, canonical string) (any, bool) {
+	if v, ok := h.Extras[canonical]; ok {
+		return v, true
+	}
+	for _, key := range legacyKeys[canonical] {
+		if v, ok := h.Extras[key]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func set(h *cloudevent.CloudEventHeader, canonical string, v any) {
+	if h.Extras == nil {
+		h.Extras = make(map[string]any)
+	}
+	for _, key := range legacyKeys[canonical] {
+		delete(h.Extras, key)
+	}
+	h.Extras[canonical] = v
+}
+
+func lookupString(h cloudevent.CloudEventHeader, canonical string) (string, bool) {
+	v, ok := lookup(h, canonical)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok && s != ""
+}
+
+// VehicleTokenID returns the vehicle token ID. Numbers, json.Number values and decimal strings
+// are accepted; the ID must be a non-negative integer.
+func VehicleTokenID(h cloudevent.CloudEventHeader) (*big.Int, bool) {
+	v, ok := lookup(h, VehicleTokenIDKey)
+	if !ok {
+		return nil, false
+	}
+	var text string
+	switch n := v.(type) {
+	case string:
+		text = n
+	case json.Number:
+		text = n.String()
+	case float64:
+		// Out-of-range conversions to uint64 are implementation-defined, so check the range first.
+		if n < 0 || n >= 1<<64 || math.IsNaN(n) || n != math.Trunc(n) {
+			return nil, false
+		}
+		return new(big.Int).SetUint64(uint64(n)), true
+	case int:
+		text = strconv.Itoa(n)
+	case int64:
+		text = strconv.FormatInt(n, 10)
+	case uint64:
+		text = strconv.FormatUint(n, 10)
+	case *big.Int:
+		if n == nil {
+			return nil, false
+		}
+		text = n.String()
+	default:
+		return nil, false
+	}
+	id, ok := new(big.Int).SetString(text, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, false
+	}
+	return id, true
+}
+
+// SetVehicleTokenID sets the vehicle token ID. It is stored as a json.Number so that it is
+// encoded as an exact JSON number. A nil or negative id is rejected, since VehicleTokenID
+// would not read it back.
+func SetVehicleTokenID(h *cloudevent.CloudEventHeader, id *big.Int) error {
+	if id == nil || id.Sign() < 0 {
+		return fmt.Errorf("dimoext: invalid vehicle token ID %v", id)
+	}
+	set(h, VehicleTokenIDKey, json.Number(id.String()))
+	return nil
+}
+
+// IntegrationID returns the integration ID.
+func IntegrationID(h cloudevent.CloudEventHeader) (string, bool) {
+	return lookupString(h, IntegrationIDKey)
+}
+
+// SetIntegrationID sets the integration ID.
+func SetIntegrationID(h *cloudevent.CloudEventHeader, id string) {
+	set(h, IntegrationIDKey, id)
+}
+
+// DeviceDefinitionID returns the device definition ID.
+func DeviceDefinitionID(h cloudevent.CloudEventHeader) (string, bool) {
+	return lookupString(h, DeviceDefinitionIDKey)
+}
+
+// SetDeviceDefinitionID sets the device definition ID.
+func SetDeviceDefinitionID(h *cloudevent.CloudEventHeader, id string) {
+	set(h, DeviceDefinitionIDKey, id)
+}
+
+// RegionCode returns the region code.
+func RegionCode(h cloudevent.CloudEventHeader) (string, bool) {
+	return lookupString(h, RegionCodeKey)
+}
+
+// SetRegionCode sets the region code.
+func SetRegionCode(h *cloudevent.CloudEventHeader, code string) {
+	set(h, RegionCodeKey, code)
+}
+
+// NormalizeDIMOExtensions moves values stored under legacy spellings to the canonical keys.
+// When several spellings are present the canonical key wins, then the legacy spellings in
+// lookup order; the others are dropped.
+func NormalizeDIMOExtensions(h *cloudevent.CloudEventHeader) {
+	for _, canonical := range CanonicalKeys() {
+		if v, ok := lookup(*h, canonical); ok {
+			set(h, canonical, v)
+		}
+	}
+}
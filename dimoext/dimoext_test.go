@@ -0,0 +1,132 @@
+package dimoext_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/DIMO-Network/cloudevent/dimoext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVehicleTokenID(t *testing.T) {
+	t.Parallel()
+
+	maxUint64, _ := new(big.Int).SetString("18446744073709551615", 10)
+	tests := []struct {
+		name   string
+		extras map[string]any
+		want   *big.Int
+	}{
+		{name: "canonical number", extras: map[string]any{"vehicletokenid": float64(123)}, want: big.NewInt(123)},
+		{name: "camelCase string", extras: map[string]any{"vehicleTokenId": "123"}, want: big.NewInt(123)},
+		{name: "upper ID", extras: map[string]any{"vehicleTokenID": float64(7)}, want: big.NewInt(7)},
+		{name: "snake_case", extras: map[string]any{"vehicle_token_id": "7"}, want: big.NewInt(7)},
+		{name: "PascalCase", extras: map[string]any{"VehicleTokenID": int64(7)}, want: big.NewInt(7)},
+		{name: "json.Number beyond float64", extras: map[string]any{"vehicletokenid": json.Number("18446744073709551615")}, want: maxUint64},
+		{name: "canonical wins", extras: map[string]any{"vehicletokenid": "1", "vehicleTokenId": "2"}, want: big.NewInt(1)},
+		{name: "fractional", extras: map[string]any{"vehicletokenid": 1.5}},
+		{name: "float beyond uint64", extras: map[string]any{"vehicletokenid": float64(1 << 64)}},
+		{name: "NaN", extras: map[string]any{"vehicletokenid": math.NaN()}},
+		{name: "infinity", extras: map[string]any{"vehicletokenid": math.Inf(1)}},
+		{name: "negative", extras: map[string]any{"vehicletokenid": "-1"}},
+		{name: "not a number", extras: map[string]any{"vehicletokenid": "abc"}},
+		{name: "wrong type", extras: map[string]any{"vehicletokenid": true}},
+		{name: "missing", extras: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := dimoext.VehicleTokenID(cloudevent.CloudEventHeader{Extras: tt.extras})
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, 0, tt.want.Cmp(got), "got %s", got)
+		})
+	}
+}
+
+func TestStringExtensions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key    string
+		getter func(cloudevent.CloudEventHeader) (string, bool)
+	}{
+		{key: "integrationId", getter: dimoext.IntegrationID},
+		{key: "integration_id", getter: dimoext.IntegrationID},
+		{key: "IntegrationID", getter: dimoext.IntegrationID},
+		{key: "devicedefinitionid", getter: dimoext.DeviceDefinitionID},
+		{key: "deviceDefinitionId", getter: dimoext.DeviceDefinitionID},
+		{key: "device_definition_id", getter: dimoext.DeviceDefinitionID},
+		{key: "regioncode", getter: dimoext.RegionCode},
+		{key: "regionCode", getter: dimoext.RegionCode},
+		{key: "region_code", getter: dimoext.RegionCode},
+	}
+	for _, tt := range tests {
+		got, ok := tt.getter(cloudevent.CloudEventHeader{Extras: map[string]any{tt.key: "value"}})
+		assert.True(t, ok, tt.key)
+		assert.Equal(t, "value", got, tt.key)
+
+		_, ok = tt.getter(cloudevent.CloudEventHeader{Extras: map[string]any{tt.key: float64(1)}})
+		assert.False(t, ok, "%s with a non-string value", tt.key)
+	}
+	_, ok := dimoext.RegionCode(cloudevent.CloudEventHeader{})
+	assert.False(t, ok)
+}
+
+func TestSetters(t *testing.T) {
+	t.Parallel()
+
+	hdr := cloudevent.CloudEventHeader{Extras: map[string]any{"vehicleTokenId": "1", "integration_id": "old"}}
+	require.NoError(t, dimoext.SetVehicleTokenID(&hdr, big.NewInt(42)))
+	dimoext.SetIntegrationID(&hdr, "int-1")
+	dimoext.SetDeviceDefinitionID(&hdr, "dd-1")
+	dimoext.SetRegionCode(&hdr, "eu")
+	assert.Equal(t, map[string]any{
+		"vehicletokenid":     json.Number("42"),
+		"integrationid":      "int-1",
+		"devicedefinitionid": "dd-1",
+		"regioncode":         "eu",
+	}, hdr.Extras)
+
+	encoded, err := json.Marshal(hdr)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"vehicletokenid":42`)
+
+	// Values VehicleTokenID would not read back are rejected and leave the header unchanged.
+	require.Error(t, dimoext.SetVehicleTokenID(&hdr, nil))
+	require.Error(t, dimoext.SetVehicleTokenID(&hdr, big.NewInt(-1)))
+	assert.Equal(t, json.Number("42"), hdr.Extras["vehicletokenid"])
+}
+
+func TestNormalizeDIMOExtensions(t *testing.T) {
+	t.Parallel()
+
+	hdr := cloudevent.CloudEventHeader{Extras: map[string]any{
+		"vehicleTokenId":       float64(5),
+		"vehicle_token_id":     "6",
+		"integrationid":        "canonical",
+		"integrationId":        "legacy",
+		"device_definition_id": "dd",
+		"other":                "kept",
+	}}
+	dimoext.NormalizeDIMOExtensions(&hdr)
+	assert.Equal(t, map[string]any{
+		"vehicletokenid":     float64(5),
+		"integrationid":      "canonical",
+		"devicedefinitionid": "dd",
+		"other":              "kept",
+	}, hdr.Extras)
+
+	empty := cloudevent.CloudEventHeader{}
+	dimoext.NormalizeDIMOExtensions(&empty)
+	assert.Nil(t, empty.Extras)
+
+	assert.Equal(t, []string{"vehicleTokenId", "vehicleTokenID", "vehicle_token_id", "VehicleTokenID"}, dimoext.LegacyKeys(dimoext.VehicleTokenIDKey))
+}
@@ -0,0 +1,35 @@
+package cloudevent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned when decoding a CloudEvent fails. They let callers tell a broken envelope
+// from a broken payload, e.g. to reject the message permanently instead of retrying.
+// The underlying error, such as a *json.SyntaxError, is preserved in the chain.
+var (
+	// ErrMalformedEnvelope is returned when the event envelope or a header attribute is invalid.
+	ErrMalformedEnvelope = errors.New("cloudevent: malformed envelope")
+	// ErrMalformedData is returned when data or data_base64 cannot be decoded.
+	ErrMalformedData = errors.New("cloudevent: malformed data")
+	// ErrMalformedExtra is returned, wrapped in an *ExtraError, when an extension attribute is invalid.
+	ErrMalformedExtra = errors.New("cloudevent: malformed extra")
+)
+
+// ExtraError reports an invalid extension attribute. It matches ErrMalformedExtra with errors.Is.
+type ExtraError struct {
+	// Key is the name of the extension attribute.
+	Key string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ExtraError) Error() string {
+	return fmt.Sprintf("%s %q: %v", ErrMalformedExtra, e.Key, e.Err)
+}
+
+// Unwrap returns ErrMalformedExtra and the underlying error.
+func (e *ExtraError) Unwrap() []error {
+	return []error{ErrMalformedExtra, e.Err}
+}
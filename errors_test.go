@@ -0,0 +1,62 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal_MalformedEnvelope(t *testing.T) {
+	t.Parallel()
+
+	for name, input := range map[string]string{
+		"not an object":        `[1,2]`,
+		"time not a string":    `{"id":"1","time":12}`,
+		"invalid time":         `{"id":"1","time":"yesterday"}`,
+		"data_base64 not text": `{"id":"1","data_base64":12}`,
+		"data and data_base64": `{"id":"1","data":{},"data_base64":"e30="}`,
+	} {
+		var ev cloudevent.RawEvent
+		err := json.Unmarshal([]byte(input), &ev)
+		require.ErrorIs(t, err, cloudevent.ErrMalformedEnvelope, name)
+		assert.NotErrorIs(t, err, cloudevent.ErrMalformedData, name)
+	}
+
+	_, err := cloudevent.DecodeHeader([]byte(`{"id":`))
+	require.ErrorIs(t, err, cloudevent.ErrMalformedEnvelope)
+	var syntaxErr *json.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr, "the json error is preserved")
+}
+
+func TestUnmarshal_MalformedData(t *testing.T) {
+	t.Parallel()
+
+	var typed cloudevent.CloudEvent[struct{ Speed float64 }]
+	err := json.Unmarshal([]byte(`{"id":"1","data":{"Speed":"fast"}}`), &typed)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, err, &typeErr, "the json error is preserved")
+
+	var raw cloudevent.RawEvent
+	err = json.Unmarshal([]byte(`{"id":"1","data_base64":"not base64!"}`), &raw)
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+	assert.NotErrorIs(t, err, cloudevent.ErrMalformedEnvelope)
+}
+
+func TestUnmarshal_MalformedExtra(t *testing.T) {
+	t.Parallel()
+
+	// json.Unmarshal validates the document first, so call UnmarshalJSON directly.
+	var ev cloudevent.RawEvent
+	err := ev.UnmarshalJSON([]byte(`{"id":"1","region":[1,}`))
+	require.ErrorIs(t, err, cloudevent.ErrMalformedExtra)
+
+	var extraErr *cloudevent.ExtraError
+	require.True(t, errors.As(err, &extraErr))
+	assert.Equal(t, "region", extraErr.Key)
+	assert.Contains(t, err.Error(), `"region"`)
+}
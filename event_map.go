@@ -0,0 +1,170 @@
+package cloudevent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ToMap returns the event as a map with the same shape as its JSON encoding: header attributes
+// under their JSON names, extras merged at the top level, and data decoded into any with
+// numbers as json.Number, so EventFromMap re-encodes them exactly. Data that MarshalJSON would write as data_base64 is returned as a base64 string
+// under "data_base64". Extras values are copied as is, so json.Number values are preserved;
+// extras named like a header attribute or data are dropped.
+func (c CloudEvent[A]) ToMap() (map[string]any, error) {
+	m := make(map[string]any, 8+len(c.Extras))
+	for k, v := range c.Extras {
+		if !isReservedExtraKey(k) {
+			m[k] = v
+		}
+	}
+	m["specversion"] = SpecVersion
+	m["type"] = c.Type
+	m["source"] = c.Source
+	m["subject"] = c.Subject
+	m["id"] = c.ID
+	m["time"] = c.Time.Format(time.RFC3339Nano)
+	m["producer"] = c.Producer
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	setIfNotEmpty("datacontenttype", c.DataContentType)
+	setIfNotEmpty("dataschema", c.DataSchema)
+	setIfNotEmpty("dataversion", c.DataVersion)
+	setIfNotEmpty("signature", c.Signature)
+	setIfNotEmpty("raweventid", c.RawEventID)
+	if len(c.Tags) > 0 {
+		tags := make([]any, len(c.Tags))
+		for i, tag := range c.Tags {
+			tags[i] = tag
+		}
+		m["tags"] = tags
+	}
+
+	if c.DataBase64 != "" {
+		m["data_base64"] = c.DataBase64
+		return m, nil
+	}
+	raw, ok := (any)(c.Data).(json.RawMessage)
+	if ok {
+		if len(raw) == 0 {
+			return m, nil
+		}
+		if !IsJSONContentType(c.DataContentType) && (c.DataContentType != "" || !json.Valid(raw)) {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(raw)
+			return m, nil
+		}
+	} else {
+		var err error
+		if raw, err = json.Marshal(c.Data); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedData, err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedData, err)
+	}
+	m["data"] = data
+	return m, nil
+}
+
+// EventFromMap builds an event from a map shaped like its JSON encoding, as returned by ToMap.
+// Header attributes are taken from their JSON names and every other key except data and
+// data_base64 becomes an extra, as in UnmarshalJSON. time may be an RFC 3339 string or a
+// time.Time. data is re-encoded as JSON; data_base64 is decoded into Data.
+func EventFromMap(m map[string]any) (RawEvent, error) {
+	var ev RawEvent
+	ev.SpecVersion = SpecVersion
+	strFields := map[string]*string{
+		"type": &ev.Type, "source": &ev.Source, "subject": &ev.Subject, "id": &ev.ID,
+		"datacontenttype": &ev.DataContentType, "dataschema": &ev.DataSchema, "dataversion": &ev.DataVersion,
+		"producer": &ev.Producer, "signature": &ev.Signature, "raweventid": &ev.RawEventID,
+	}
+	for k, v := range m {
+		if dst, ok := strFields[k]; ok {
+			s, ok := v.(string)
+			if !ok {
+				return RawEvent{}, fmt.Errorf("%w: %s must be a string, got %T", ErrMalformedEnvelope, k, v)
+			}
+			*dst = s
+			continue
+		}
+		switch k {
+		case "specversion", "data", "data_base64":
+		case "time":
+			switch t := v.(type) {
+			case time.Time:
+				ev.Time = t
+			case string:
+				parsed, err := time.Parse(time.RFC3339Nano, t)
+				if err != nil {
+					return RawEvent{}, fmt.Errorf("%w: invalid time: %w", ErrMalformedEnvelope, err)
+				}
+				ev.Time = parsed
+			default:
+				return RawEvent{}, fmt.Errorf("%w: time must be a string, got %T", ErrMalformedEnvelope, v)
+			}
+		case "tags":
+			tags, err := mapTags(v)
+			if err != nil {
+				return RawEvent{}, err
+			}
+			ev.Tags = tags
+		default:
+			if ev.Extras == nil {
+				ev.Extras = make(map[string]any)
+			}
+			ev.Extras[k] = v
+		}
+	}
+
+	data, hasData := m["data"]
+	db64, hasBase64 := m["data_base64"]
+	switch {
+	case hasData && hasBase64:
+		return RawEvent{}, fmt.Errorf("%w: both \"data\" and \"data_base64\" present; only one allowed", ErrMalformedEnvelope)
+	case hasData:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return RawEvent{}, fmt.Errorf("%w: %w", ErrMalformedData, err)
+		}
+		ev.Data = raw
+	case hasBase64:
+		s, ok := db64.(string)
+		if !ok {
+			return RawEvent{}, fmt.Errorf("%w: data_base64 must be a string, got %T", ErrMalformedEnvelope, db64)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return RawEvent{}, fmt.Errorf("%w: invalid data_base64: %w", ErrMalformedData, err)
+		}
+		ev.Data = decoded
+		ev.DataBase64 = s
+	}
+	return ev, nil
+}
+
+func mapTags(v any) ([]string, error) {
+	switch tags := v.(type) {
+	case []string:
+		return tags, nil
+	case []any:
+		out := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			s, ok := tag.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: tags must be strings, got %T", ErrMalformedEnvelope, tag)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: tags must be an array, got %T", ErrMalformedEnvelope, v)
+	}
+}
@@ -0,0 +1,158 @@
+package cloudevent_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mapFixture() cloudevent.RawEvent {
+	return cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			SpecVersion:     cloudevent.SpecVersion,
+			Type:            cloudevent.TypeStatus,
+			Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+			Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+			ID:              "1",
+			Time:            time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC),
+			DataContentType: "application/json",
+			DataVersion:     "v2",
+			Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+			Signature:       "0xdeadbeef",
+			Tags:            []string{"a", "b"},
+			Extras:          map[string]any{"count": json.Number("3"), "meta": map[string]any{"ids": []any{"x", json.Number("1.5")}}},
+		},
+		Data: json.RawMessage(`{"signals":[{"name":"speed","value":42.5}],"nested":{"ok":true,"none":null}}`),
+	}
+}
+
+// viaJSON returns the map the JSON path produces for ev, with numbers as json.Number.
+func viaJSON(t *testing.T, ev cloudevent.RawEvent) map[string]any {
+	t.Helper()
+	encoded, err := json.Marshal(ev)
+	require.NoError(t, err)
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	var m map[string]any
+	require.NoError(t, dec.Decode(&m))
+	return m
+}
+
+func TestToMap_MatchesJSON(t *testing.T) {
+	t.Parallel()
+
+	ev := mapFixture()
+	m, err := ev.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, viaJSON(t, ev), m)
+
+	binary := mapFixture()
+	binary.DataContentType = "application/octet-stream"
+	binary.Data = json.RawMessage{0xff, 0x00}
+	m, err = binary.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, viaJSON(t, binary), m)
+
+	empty := cloudevent.RawEvent{}
+	m, err = empty.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, viaJSON(t, empty), m)
+
+	typed := cloudevent.CloudEvent[map[string]int]{Data: map[string]int{"speed": 1}}
+	m, err = typed.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"speed": json.Number("1")}, m["data"])
+
+	// Extras never override header attributes.
+	clash := mapFixture()
+	clash.Extras = map[string]any{"id": "fake", "data": "fake"}
+	m, err = clash.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, "1", m["id"])
+	assert.IsType(t, map[string]any{}, m["data"])
+}
+
+func TestEventFromMap_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for name, ev := range map[string]cloudevent.RawEvent{
+		"json":   mapFixture(),
+		"binary": {CloudEventHeader: cloudevent.CloudEventHeader{ID: "2", DataContentType: "application/octet-stream"}, Data: json.RawMessage{0xff}},
+		"empty":  {},
+	} {
+		m, err := ev.ToMap()
+		require.NoError(t, err, name)
+		got, err := cloudevent.EventFromMap(m)
+		require.NoError(t, err, name)
+
+		encoded, err := json.Marshal(ev)
+		require.NoError(t, err, name)
+		want, err := cloudevent.Unmarshal[json.RawMessage](encoded, cloudevent.WithUseNumber())
+		require.NoError(t, err, name)
+		assert.Equal(t, want.CloudEventHeader, got.CloudEventHeader, name)
+		if len(want.Data) == 0 {
+			assert.Empty(t, got.Data, name)
+		} else if want.DataBase64 != "" {
+			assert.Equal(t, want.Data, got.Data, name)
+			assert.Equal(t, want.DataBase64, got.DataBase64, name)
+		} else {
+			assert.JSONEq(t, string(want.Data), string(got.Data), name)
+		}
+	}
+}
+
+func TestEventFromMap_LargeIntegerData(t *testing.T) {
+	t.Parallel()
+
+	ev := mapFixture()
+	ev.Data = json.RawMessage(`{"odometer":12345678901234567890,"speed":42.5}`)
+	m, err := ev.ToMap()
+	require.NoError(t, err)
+	got, err := cloudevent.EventFromMap(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(ev.Data), string(got.Data))
+	assert.Contains(t, string(got.Data), "12345678901234567890")
+}
+
+func TestEventFromMap_NumericExtras(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id":"1","time":"2024-06-01T12:00:00Z","vehicletokenid":18446744073709551615}`
+	ev, err := cloudevent.Unmarshal[json.RawMessage]([]byte(input), cloudevent.WithUseNumber())
+	require.NoError(t, err)
+	m, err := ev.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), m["vehicletokenid"])
+
+	got, err := cloudevent.EventFromMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), got.Extras["vehicletokenid"])
+}
+
+func TestEventFromMap_Errors(t *testing.T) {
+	t.Parallel()
+
+	for name, m := range map[string]map[string]any{
+		"id not a string":      {"id": 1},
+		"bad time":             {"time": "yesterday"},
+		"time wrong type":      {"time": 1},
+		"tags not strings":     {"tags": []any{1}},
+		"tags not an array":    {"tags": "a"},
+		"data and data_base64": {"data": 1, "data_base64": "AQ=="},
+	} {
+		_, err := cloudevent.EventFromMap(m)
+		require.ErrorIs(t, err, cloudevent.ErrMalformedEnvelope, name)
+	}
+	_, err := cloudevent.EventFromMap(map[string]any{"data_base64": "!!"})
+	require.ErrorIs(t, err, cloudevent.ErrMalformedData)
+
+	ev, err := cloudevent.EventFromMap(map[string]any{"time": time.Unix(0, 0).UTC(), "tags": []string{"a"}})
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(0, 0).UTC(), ev.Time)
+	assert.Equal(t, []string{"a"}, ev.Tags)
+}
@@ -0,0 +1,162 @@
+package cloudevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// GetExtra returns the extra stored under key as a T. Besides values already of type T, numeric
+// types are converted from whatever number type the extra holds, so an integer decoded from JSON
+// as float64 or json.Number can be read as an int64. Conversions to integer types fail if the
+// value is fractional or overflows. Conversions to floating-point types fail only on overflow;
+// integers beyond 2^53 are rounded to the nearest representable value, as Go conversions do.
+// It returns false if the extra is missing or cannot be represented as a T.
+func GetExtra[T any](h CloudEventHeader, key string) (T, bool) {
+	var out T
+	v, ok := h.Extras[key]
+	if !ok {
+		return out, false
+	}
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	rv := reflect.ValueOf(&out).Elem()
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := extraInt64(v)
+		if !ok || rv.OverflowInt(i) {
+			return out, false
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, ok := extraUint64(v)
+		if !ok || rv.OverflowUint(u) {
+			return out, false
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := extraFloat64(v)
+		if !ok || rv.OverflowFloat(f) {
+			return out, false
+		}
+		rv.SetFloat(f)
+	default:
+		return out, false
+	}
+	return out, true
+}
+
+// SetExtra sets the extension attribute key, allocating Extras if needed. It fails if key names
+// a header attribute or data, since such an extra would be dropped when the event is encoded.
+func (c *CloudEventHeader) SetExtra(key string, value any) error {
+	if isReservedExtraKey(key) {
+		return fmt.Errorf("cloudevent: extra %q collides with a reserved attribute", key)
+	}
+	if c.Extras == nil {
+		c.Extras = make(map[string]any)
+	}
+	c.Extras[key] = value
+	return nil
+}
+
+// GetExtraString returns the string extra stored under key.
+func (c CloudEventHeader) GetExtraString(key string) (string, bool) {
+	return GetExtra[string](c, key)
+}
+
+// GetExtraInt64 returns the integer extra stored under key. See GetExtra for the conversions applied.
+func (c CloudEventHeader) GetExtraInt64(key string) (int64, bool) {
+	return GetExtra[int64](c, key)
+}
+
+// GetExtraFloat64 returns the numeric extra stored under key as a float64.
+func (c CloudEventHeader) GetExtraFloat64(key string) (float64, bool) {
+	return GetExtra[float64](c, key)
+}
+
+// GetExtraBool returns the boolean extra stored under key.
+func (c CloudEventHeader) GetExtraBool(key string) (bool, bool) {
+	return GetExtra[bool](c, key)
+}
+
+// extraInt64 converts an integer-valued number of any Go or decoded JSON type to int64.
+func extraInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), n <= math.MaxInt64
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float32:
+		return extraInt64(float64(n))
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// extraUint64 converts a non-negative integer-valued number of any Go or decoded JSON type to
+// uint64, including values above math.MaxInt64 such as token IDs decoded with WithUseNumber.
+func extraUint64(v any) (uint64, bool) {
+	switch n := v.(type) {
+	case uint:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case float64:
+		if math.IsNaN(n) || n < 0 || n >= 1<<64 || n != math.Trunc(n) {
+			return 0, false
+		}
+		return uint64(n), true
+	case json.Number:
+		u, err := strconv.ParseUint(n.String(), 10, 64)
+		return u, err == nil
+	default:
+		i, ok := extraInt64(v)
+		return uint64(i), ok && i >= 0
+	}
+}
+
+// extraFloat64 converts a number of any Go or decoded JSON type to float64.
+func extraFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case uint, uint64:
+		u, ok := extraUint64(v)
+		return float64(u), ok
+	default:
+		i, ok := extraInt64(v)
+		return float64(i), ok
+	}
+}
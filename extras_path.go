@@ -0,0 +1,152 @@
+package cloudevent
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetExtraPath returns the value at a dot-separated path into Extras, e.g. "meta.deviceId".
+// A literal dot or backslash in a key is escaped with a backslash ("a\.b" is the key "a.b").
+// Numeric segments index into arrays. It returns false if any level is missing or the first
+// segment names a header attribute.
+func GetExtraPath(h CloudEventHeader, path string) (any, bool) {
+	segments, err := parseExtraPath(path)
+	if err != nil {
+		return nil, false
+	}
+	var cur any = h.Extras
+	for _, seg := range segments {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// GetExtraPathString returns the string at path, or false if it is missing or not a string.
+func GetExtraPathString(h CloudEventHeader, path string) (string, bool) {
+	v, ok := GetExtraPath(h, path)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// GetExtraPathFloat64 returns the number at path as a float64, or false if it is missing or
+// not a number. It converts numbers as GetExtraFloat64 does, including json.Number.
+func GetExtraPathFloat64(h CloudEventHeader, path string) (float64, bool) {
+	v, ok := GetExtraPath(h, path)
+	if !ok {
+		return 0, false
+	}
+	return extraFloat64(v)
+}
+
+// GetExtraPathInt64 returns the integer at path, or false if it is missing or not an integer
+// that fits an int64. It converts numbers as GetExtraInt64 does.
+func GetExtraPathInt64(h CloudEventHeader, path string) (int64, bool) {
+	v, ok := GetExtraPath(h, path)
+	if !ok {
+		return 0, false
+	}
+	return extraInt64(v)
+}
+
+// GetExtraPathBool returns the boolean at path, or false if it is missing or not a bool.
+func GetExtraPathBool(h CloudEventHeader, path string) (bool, bool) {
+	v, ok := GetExtraPath(h, path)
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// SetExtraPath sets the value at a dot-separated path into Extras, creating Extras and any
+// missing intermediate objects. Paths use the same syntax as GetExtraPath. An array element
+// can be replaced but arrays are not grown. It fails if the first segment names a header
+// attribute or an intermediate value is neither an object nor an array.
+func SetExtraPath(h *CloudEventHeader, path string, v any) error {
+	segments, err := parseExtraPath(path)
+	if err != nil {
+		return err
+	}
+	if h.Extras == nil {
+		h.Extras = make(map[string]any)
+	}
+	var cur any = h.Extras
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch node := cur.(type) {
+		case map[string]any:
+			if last {
+				node[seg] = v
+				return nil
+			}
+			next, ok := node[seg]
+			if !ok || next == nil {
+				next = make(map[string]any)
+				node[seg] = next
+			}
+			cur = next
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return fmt.Errorf("cloudevent: extra path %q: index %q out of range", path, seg)
+			}
+			if last {
+				node[idx] = v
+				return nil
+			}
+			if node[idx] == nil {
+				node[idx] = make(map[string]any)
+			}
+			cur = node[idx]
+		default:
+			return fmt.Errorf("cloudevent: extra path %q: %q is not an object or array", path, strings.Join(segments[:i], "."))
+		}
+	}
+	return nil
+}
+
+// parseExtraPath splits path into unescaped segments and rejects reserved first segments.
+func parseExtraPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, errors.New("cloudevent: empty extra path")
+	}
+	var segments []string
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			b.WriteByte(path[i])
+		case c == '.':
+			segments = append(segments, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	segments = append(segments, b.String())
+	if isReservedExtraKey(segments[0]) {
+		return nil, fmt.Errorf("cloudevent: extra path %q starts with reserved attribute %q", path, segments[0])
+	}
+	return segments, nil
+}
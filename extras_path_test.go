@@ -0,0 +1,96 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExtraPath(t *testing.T) {
+	t.Parallel()
+
+	var hdr cloudevent.CloudEventHeader
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id":"1",
+		"meta":{"deviceId":"abc","fw.version":"1.2","signals":[{"name":"speed","value":42.5},null],"online":true}
+	}`), &hdr))
+
+	s, ok := cloudevent.GetExtraPathString(hdr, "meta.deviceId")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", s)
+
+	s, ok = cloudevent.GetExtraPathString(hdr, `meta.fw\.version`)
+	assert.True(t, ok)
+	assert.Equal(t, "1.2", s)
+
+	f, ok := cloudevent.GetExtraPathFloat64(hdr, "meta.signals.0.value")
+	assert.True(t, ok)
+	assert.InDelta(t, 42.5, f, 0)
+
+	b, ok := cloudevent.GetExtraPathBool(hdr, "meta.online")
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	v, ok := cloudevent.GetExtraPath(hdr, "meta.signals.1")
+	assert.True(t, ok)
+	assert.Nil(t, v)
+
+	for _, path := range []string{"meta.missing", "meta.deviceId.deeper", "meta.signals.2", "meta.signals.x", "missing.deviceId", "id", ""} {
+		_, ok := cloudevent.GetExtraPath(hdr, path)
+		assert.False(t, ok, path)
+	}
+	_, ok = cloudevent.GetExtraPathString(hdr, "meta.online")
+	assert.False(t, ok, "wrong type")
+}
+
+func TestGetExtraPath_Numbers(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"id":"1","meta":{"odometer":12345678901234567,"speed":42.5}}`)
+	decoded, err := cloudevent.Unmarshal[json.RawMessage](input, cloudevent.WithUseNumber())
+	require.NoError(t, err)
+	fromMap, err := cloudevent.HeaderFromStringMap(map[string]string{"id": "1", "ext-meta": `{"odometer":12345678901234567,"speed":42.5}`})
+	require.NoError(t, err)
+	set := cloudevent.CloudEventHeader{}
+	require.NoError(t, cloudevent.SetExtraPath(&set, "meta.odometer", int64(12345678901234567)))
+	require.NoError(t, cloudevent.SetExtraPath(&set, "meta.speed", float32(42.5)))
+
+	for name, hdr := range map[string]cloudevent.CloudEventHeader{"WithUseNumber": decoded.CloudEventHeader, "string map": fromMap, "set": set} {
+		i, ok := cloudevent.GetExtraPathInt64(hdr, "meta.odometer")
+		assert.True(t, ok, name)
+		assert.Equal(t, int64(12345678901234567), i, name)
+		f, ok := cloudevent.GetExtraPathFloat64(hdr, "meta.speed")
+		assert.True(t, ok, name)
+		assert.InDelta(t, 42.5, f, 0, name)
+
+		_, ok = cloudevent.GetExtraPathInt64(hdr, "meta.speed")
+		assert.False(t, ok, "%s: fractional", name)
+	}
+}
+
+func TestSetExtraPath(t *testing.T) {
+	t.Parallel()
+
+	var hdr cloudevent.CloudEventHeader
+	require.NoError(t, cloudevent.SetExtraPath(&hdr, "meta.device.id", "abc"))
+	require.NoError(t, cloudevent.SetExtraPath(&hdr, `meta.fw\.version`, "1.2"))
+	assert.Equal(t, map[string]any{
+		"meta": map[string]any{
+			"device":     map[string]any{"id": "abc"},
+			"fw.version": "1.2",
+		},
+	}, hdr.Extras)
+
+	hdr.Extras["list"] = []any{map[string]any{"a": 1.0}, nil}
+	require.NoError(t, cloudevent.SetExtraPath(&hdr, "list.0.a", 2.0))
+	require.NoError(t, cloudevent.SetExtraPath(&hdr, "list.1.b", "new"))
+	assert.Equal(t, []any{map[string]any{"a": 2.0}, map[string]any{"b": "new"}}, hdr.Extras["list"])
+
+	require.Error(t, cloudevent.SetExtraPath(&hdr, "list.2", 1), "arrays are not grown")
+	require.Error(t, cloudevent.SetExtraPath(&hdr, "meta.device.id.deeper", 1), "cannot descend into a string")
+	require.Error(t, cloudevent.SetExtraPath(&hdr, "subject.x", 1), "reserved attribute")
+	require.Error(t, cloudevent.SetExtraPath(&hdr, "data", 1), "reserved attribute")
+}
@@ -0,0 +1,159 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExtra(t *testing.T) {
+	t.Parallel()
+
+	var h cloudevent.CloudEventHeader
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"1","name":"x","count":42,"ratio":0.5,"on":true,"big":1e30,"neg":-1}`), &h))
+
+	s, ok := h.GetExtraString("name")
+	assert.True(t, ok)
+	assert.Equal(t, "x", s)
+	_, ok = h.GetExtraString("count")
+	assert.False(t, ok)
+
+	i, ok := h.GetExtraInt64("count")
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), i)
+	_, ok = h.GetExtraInt64("ratio")
+	assert.False(t, ok, "fractional numbers are not integers")
+	_, ok = h.GetExtraInt64("big")
+	assert.False(t, ok, "out of range")
+
+	f, ok := h.GetExtraFloat64("ratio")
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, f, 0)
+
+	b, ok := h.GetExtraBool("on")
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	u8, ok := cloudevent.GetExtra[uint8](h, "count")
+	assert.True(t, ok)
+	assert.Equal(t, uint8(42), u8)
+	_, ok = cloudevent.GetExtra[uint8](h, "neg")
+	assert.False(t, ok)
+	_, ok = cloudevent.GetExtra[int8](cloudevent.CloudEventHeader{Extras: map[string]any{"n": 300}}, "n")
+	assert.False(t, ok)
+
+	n, ok := cloudevent.GetExtra[int](cloudevent.CloudEventHeader{Extras: map[string]any{"n": json.Number("7")}}, "n")
+	assert.True(t, ok)
+	assert.Equal(t, 7, n)
+	_, ok = cloudevent.GetExtra[int64](cloudevent.CloudEventHeader{Extras: map[string]any{"n": uint64(math.MaxUint64)}}, "n")
+	assert.False(t, ok)
+
+	for _, v := range []any{json.Number("18446744073709551615"), uint64(math.MaxUint64)} {
+		u64, ok := cloudevent.GetExtra[uint64](cloudevent.CloudEventHeader{Extras: map[string]any{"n": v}}, "n")
+		assert.True(t, ok, "%T", v)
+		assert.Equal(t, uint64(math.MaxUint64), u64)
+	}
+	_, ok = cloudevent.GetExtra[uint64](cloudevent.CloudEventHeader{Extras: map[string]any{"n": json.Number("18446744073709551616")}}, "n")
+	assert.False(t, ok)
+	_, ok = cloudevent.GetExtra[uint64](cloudevent.CloudEventHeader{Extras: map[string]any{"n": json.Number("-1")}}, "n")
+	assert.False(t, ok)
+
+	// Integers beyond 2^53 are rounded when read as floating point.
+	for _, v := range []any{int64(1<<53 + 1), uint64(math.MaxUint64), json.Number("9007199254740993")} {
+		f, ok := cloudevent.GetExtra[float64](cloudevent.CloudEventHeader{Extras: map[string]any{"n": v}}, "n")
+		assert.True(t, ok, "%T", v)
+		assert.NotZero(t, f)
+	}
+	_, ok = cloudevent.GetExtra[float32](cloudevent.CloudEventHeader{Extras: map[string]any{"n": 1e300}}, "n")
+	assert.False(t, ok, "overflows float32")
+
+	m, ok := cloudevent.GetExtra[map[string]any](cloudevent.CloudEventHeader{Extras: map[string]any{"m": map[string]any{"a": 1}}}, "m")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"a": 1}, m)
+
+	var empty cloudevent.CloudEventHeader
+	_, ok = empty.GetExtraString("name")
+	assert.False(t, ok)
+	_, ok = cloudevent.GetExtra[any](empty, "name")
+	assert.False(t, ok)
+}
+
+func TestSetExtra(t *testing.T) {
+	t.Parallel()
+
+	var h cloudevent.CloudEventHeader
+	require.NoError(t, h.SetExtra("region", "eu"))
+	assert.Equal(t, map[string]any{"region": "eu"}, h.Extras)
+
+	for _, key := range []string{"id", "specversion", "tags", "data", "data_base64"} {
+		require.Error(t, h.SetExtra(key, "x"), key)
+	}
+	assert.Len(t, h.Extras, 1)
+}
+
+func TestMarshalJSON_SkipsReservedExtras(t *testing.T) {
+	t.Parallel()
+
+	ev := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			ID:     "real",
+			Extras: map[string]any{"id": "fake", "data": "fake", "region": "eu"},
+		},
+		Data: json.RawMessage(`{"a":1}`),
+	}
+	out, err := json.Marshal(ev)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(out), `"id":`))
+	assert.Equal(t, 1, strings.Count(string(out), `"data":`))
+
+	var decoded cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, "real", decoded.ID)
+	assert.JSONEq(t, `{"a":1}`, string(decoded.Data))
+	assert.Equal(t, map[string]any{"region": "eu"}, decoded.Extras)
+
+	hdr, err := json.Marshal(ev.CloudEventHeader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(hdr), `"id":`))
+}
+
+func TestMarshalJSON_ExtraKeysRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	extras := map[string]any{
+		"vehicle.vin": "1HGCM82633A004352",
+		"a*b":         "star",
+		"x|y":         "pipe",
+		"0":           "numeric",
+		"#":           "hash",
+		`back\slash`:  "escape",
+		"meta.info":   map[string]any{"nested.key": "v"},
+	}
+	ev := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "1", Extras: extras},
+		Data:             json.RawMessage(`{}`),
+	}
+
+	out, err := json.Marshal(ev)
+	require.NoError(t, err)
+	var generic map[string]any
+	require.NoError(t, json.Unmarshal(out, &generic))
+	for k, v := range extras {
+		assert.Equal(t, v, generic[k], "extra %q is a top-level member", k)
+	}
+
+	var decoded cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, extras, decoded.Extras)
+
+	hdrOut, err := json.Marshal(ev.CloudEventHeader)
+	require.NoError(t, err)
+	var hdr cloudevent.CloudEventHeader
+	require.NoError(t, json.Unmarshal(hdrOut, &hdr))
+	assert.Equal(t, extras, hdr.Extras)
+}
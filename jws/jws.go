@@ -0,0 +1,191 @@
+// Package jws encodes CloudEvents as compact JWS attestations and verifies them.
+//
+// The JWS payload is the event's cloudevent.CanonicalJSON encoding, so the same event always
+// produces the same signing input. That encoding omits the signature attribute, which the JWS
+// signature takes the place of. ES256 (P-256) and ES256K (secp256k1) are supported.
+// The kid header identifies the signing key: the Ethereum address for ES256K and the
+// RFC 7638 JWK thumbprint for ES256. DecodeJWS recomputes the identity from the verified
+// key, so the signer recorded on the decoded event is never taken on trust from the token.
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/DIMO-Network/cloudevent"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// ES256 is ECDSA using P-256 and SHA-256.
+	ES256 = "ES256"
+	// ES256K is ECDSA using secp256k1 and SHA-256.
+	ES256K = "ES256K"
+
+	// ExtraJWS is the Extras key under which DecodeJWS records the verified token.
+	ExtraJWS = "jws"
+	// ExtraJWSSigner is the Extras key under which DecodeJWS records the verified signer identity.
+	ExtraJWSSigner = "jwssigner"
+
+	headerType = "cloudevents+json"
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned for algorithms other than ES256 and ES256K.
+	ErrUnsupportedAlgorithm = errors.New("jws: unsupported algorithm")
+	// ErrInvalidSignature is returned when the token's signature does not verify.
+	ErrInvalidSignature = errors.New("jws: invalid signature")
+)
+
+// Header is the protected header of a token.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// EncodeJWS signs the canonical JSON encoding of e with signer and returns a compact JWS.
+// signer's public key must be an ECDSA key on the curve alg requires.
+func EncodeJWS(e cloudevent.RawEvent, signer crypto.Signer, alg string) (string, error) {
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("jws: signer must have an ECDSA public key, got %T", signer.Public())
+	}
+	kid, err := keyID(alg, pub)
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(Header{Alg: alg, Kid: kid, Typ: headerType})
+	if err != nil {
+		return "", fmt.Errorf("jws: failed to encode header: %w", err)
+	}
+	payload, err := cloudevent.CanonicalJSON(e)
+	if err != nil {
+		return "", fmt.Errorf("jws: failed to encode event: %w", err)
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	der, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("jws: failed to sign event: %w", err)
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return "", fmt.Errorf("jws: failed to parse signature: %w", err)
+	}
+	raw := make([]byte, 64)
+	sig.R.FillBytes(raw[:32])
+	sig.S.FillBytes(raw[32:])
+	return signingInput + "." + encodeSegment(raw), nil
+}
+
+// DecodeJWS verifies token with the key returned by keyfunc for its header and returns the event.
+// The header's alg must be ES256 or ES256K and the key must match it, including the kid if one is set.
+// The decoded event records token under ExtraJWS and the signer identity under ExtraJWSSigner.
+func DecodeJWS(token string, keyfunc func(Header) (crypto.PublicKey, error)) (cloudevent.RawEvent, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return cloudevent.RawEvent{}, errors.New("jws: token must have three segments")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: failed to decode header: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: failed to decode header: %w", err)
+	}
+	if header.Alg != ES256 && header.Alg != ES256K {
+		return cloudevent.RawEvent{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	key, err := keyfunc(header)
+	if err != nil {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: failed to get key: %w", err)
+	}
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: key must be an ECDSA public key, got %T", key)
+	}
+	signer, err := keyID(header.Alg, pub)
+	if err != nil {
+		return cloudevent.RawEvent{}, err
+	}
+	if header.Kid != "" && header.Kid != signer {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: key does not match kid %q", header.Kid)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return cloudevent.RawEvent{}, ErrInvalidSignature
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		return cloudevent.RawEvent{}, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: failed to decode payload: %w", err)
+	}
+	var ev cloudevent.RawEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return cloudevent.RawEvent{}, fmt.Errorf("jws: failed to decode event: %w", err)
+	}
+	if ev.Extras == nil {
+		ev.Extras = make(map[string]any)
+	}
+	ev.Extras[ExtraJWS] = token
+	ev.Extras[ExtraJWSSigner] = signer
+	return ev, nil
+}
+
+// keyID checks that pub is on the curve alg requires and returns its identity.
+func keyID(alg string, pub *ecdsa.PublicKey) (string, error) {
+	switch alg {
+	case ES256:
+		if pub.Curve != elliptic.P256() {
+			return "", fmt.Errorf("jws: %s requires a P-256 key", alg)
+		}
+		return thumbprint(pub), nil
+	case ES256K:
+		if !isSecp256k1(pub.Curve) {
+			return "", fmt.Errorf("jws: %s requires a secp256k1 key", alg)
+		}
+		return ethcrypto.PubkeyToAddress(*pub).Hex(), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+func isSecp256k1(curve elliptic.Curve) bool {
+	want := ethcrypto.S256().Params()
+	got := curve.Params()
+	return got.P.Cmp(want.P) == 0 && got.N.Cmp(want.N) == 0 && got.B.Cmp(want.B) == 0
+}
+
+// thumbprint returns the RFC 7638 JWK thumbprint of a P-256 key.
+func thumbprint(pub *ecdsa.PublicKey) string {
+	coord := func(v *big.Int) string {
+		return encodeSegment(v.FillBytes(make([]byte, 32)))
+	}
+	jwk := `{"crv":"P-256","kty":"EC","x":"` + coord(pub.X) + `","y":"` + coord(pub.Y) + `"}`
+	sum := sha256.Sum256([]byte(jwk))
+	return encodeSegment(sum[:])
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,153 @@
+package jws_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/DIMO-Network/cloudevent/jws"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() cloudevent.RawEvent {
+	return cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			SpecVersion: cloudevent.SpecVersion,
+			ID:          "1",
+			Type:        cloudevent.TypeAttestation,
+			Source:      "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+			Subject:     "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+			Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		Data: json.RawMessage(`{"odometer":1000}`),
+	}
+}
+
+func staticKey(pub crypto.PublicKey) func(jws.Header) (crypto.PublicKey, error) {
+	return func(jws.Header) (crypto.PublicKey, error) { return pub, nil }
+}
+
+func TestJWS_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	secp, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		alg    string
+		key    *ecdsa.PrivateKey
+		signer string
+	}{
+		{alg: jws.ES256, key: p256},
+		{alg: jws.ES256K, key: secp, signer: ethcrypto.PubkeyToAddress(secp.PublicKey).Hex()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			t.Parallel()
+
+			ev := testEvent()
+			token, err := jws.EncodeJWS(ev, tt.key, tt.alg)
+			require.NoError(t, err)
+
+			var header jws.Header
+			got, err := jws.DecodeJWS(token, func(h jws.Header) (crypto.PublicKey, error) {
+				header = h
+				return &tt.key.PublicKey, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, header.Alg)
+			if tt.signer != "" {
+				assert.Equal(t, tt.signer, header.Kid)
+			}
+			assert.Equal(t, header.Kid, got.Extras[jws.ExtraJWSSigner])
+			assert.Equal(t, token, got.Extras[jws.ExtraJWS])
+
+			got.Extras = nil
+			assert.Equal(t, ev.CloudEventHeader, got.CloudEventHeader)
+			assert.JSONEq(t, string(ev.Data), string(got.Data))
+		})
+	}
+}
+
+func TestEncodeJWS_CanonicalPayload(t *testing.T) {
+	t.Parallel()
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	ev := testEvent()
+	ev.Extras = map[string]any{"region": "eu", "fleet": "a", "meta": map[string]any{"b": 1, "a": 2}}
+
+	want, err := cloudevent.CanonicalJSON(ev)
+	require.NoError(t, err)
+	for range 5 {
+		token, err := jws.EncodeJWS(ev, key, jws.ES256K)
+		require.NoError(t, err)
+		payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(payload))
+	}
+}
+
+func TestDecodeJWS_TamperedPayload(t *testing.T) {
+	t.Parallel()
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	token, err := jws.EncodeJWS(testEvent(), key, jws.ES256K)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	tampered := testEvent()
+	tampered.Data = json.RawMessage(`{"odometer":1}`)
+	payload, err := json.Marshal(tampered)
+	require.NoError(t, err)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	_, err = jws.DecodeJWS(strings.Join(parts, "."), staticKey(&key.PublicKey))
+	require.ErrorIs(t, err, jws.ErrInvalidSignature)
+}
+
+func TestDecodeJWS_Rejects(t *testing.T) {
+	t.Parallel()
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token, err := jws.EncodeJWS(testEvent(), p256, jws.ES256)
+	require.NoError(t, err)
+
+	// A key other than the one named by kid.
+	_, err = jws.DecodeJWS(token, staticKey(&other.PublicKey))
+	require.Error(t, err)
+
+	// A key on the wrong curve for the algorithm.
+	_, err = jws.EncodeJWS(testEvent(), p256, jws.ES256K)
+	require.Error(t, err)
+
+	// Algorithms outside the allowlist.
+	_, err = jws.EncodeJWS(testEvent(), p256, "HS256")
+	require.ErrorIs(t, err, jws.ErrUnsupportedAlgorithm)
+	parts := strings.Split(token, ".")
+	for _, alg := range []string{"none", "HS256", "ES384"} {
+		header, err := json.Marshal(jws.Header{Alg: alg})
+		require.NoError(t, err)
+		parts[0] = base64.RawURLEncoding.EncodeToString(header)
+		_, err = jws.DecodeJWS(strings.Join(parts, "."), staticKey(&p256.PublicKey))
+		require.ErrorIs(t, err, jws.ErrUnsupportedAlgorithm, alg)
+	}
+
+	_, err = jws.DecodeJWS("not-a-token", staticKey(&p256.PublicKey))
+	require.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package cloudevent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Finding codes reported by Lint. The codes are stable and safe to aggregate on.
+const (
+	// LintMissingID is an error: the id attribute is empty.
+	LintMissingID = "missing_id"
+	// LintMissingSource is an error: the source attribute is empty.
+	LintMissingSource = "missing_source"
+	// LintMissingType is an error: the type attribute is empty.
+	LintMissingType = "missing_type"
+	// LintMissingSubject is an error: the subject attribute is empty.
+	LintMissingSubject = "missing_subject"
+	// LintMissingTime is an error: the time attribute is zero.
+	LintMissingTime = "missing_time"
+	// LintInvalidSpecVersion is an error: specversion is not SpecVersion.
+	LintInvalidSpecVersion = "invalid_specversion"
+	// LintReservedExtraKey is an error: an extra uses the name of a header attribute or of data.
+	LintReservedExtraKey = "reserved_extra_key"
+	// LintInvalidExtra is an error: an extra value cannot be encoded as JSON.
+	LintInvalidExtra = "invalid_extra"
+	// LintMissingData is an error reported only by ValidateWithData: the event has no data.
+	LintMissingData = "missing_data"
+
+	// LintMissingProducer is a warning: the producer attribute is empty.
+	LintMissingProducer = "missing_producer"
+	// LintNonDIDSubject is a warning: the subject is not a DID.
+	LintNonDIDSubject = "non_did_subject"
+	// LintUppercaseExtraKey is a warning: an extra name contains upper-case letters,
+	// which the CloudEvents spec does not allow for extension attributes.
+	LintUppercaseExtraKey = "uppercase_extra_key"
+	// LintOversizedExtras is a warning: the JSON encoding of Extras exceeds LintMaxExtrasSize.
+	LintOversizedExtras = "oversized_extras"
+	// LintFutureTime is a warning: time is more than LintMaxClockSkew ahead of the current time.
+	LintFutureTime = "future_time"
+)
+
+const (
+	// LintMaxExtrasSize is the encoded size of Extras above which Lint warns.
+	LintMaxExtrasSize = 16 * 1024
+	// LintMaxClockSkew is how far in the future an event time may be before Lint warns.
+	LintMaxClockSkew = 5 * time.Minute
+)
+
+// Finding is a single problem reported by Lint.
+type Finding struct {
+	// Code is one of the Lint* codes.
+	Code string
+	// Field is the JSON name of the attribute the finding is about.
+	Field string
+	// Message describes the problem.
+	Message string
+}
+
+// String formats the finding as "code (field): message".
+func (f Finding) String() string {
+	return f.Code + " (" + f.Field + "): " + f.Message
+}
+
+// Error implements error, so that the findings joined by Validate can be inspected with errors.As.
+func (f Finding) Error() string {
+	return "cloudevent: " + f.String()
+}
+
+// Lint checks h and returns warnings, which are suspicious but tolerated in historical
+// traffic, separately from errors, which make the event invalid and are what Validate reports.
+// Findings are ordered by attribute, then by extra name for extras.
+func Lint(h CloudEventHeader) (warns, errs []Finding) {
+	if h.SpecVersion != SpecVersion {
+		errs = append(errs, Finding{LintInvalidSpecVersion, "specversion", fmt.Sprintf("specversion must be %q, got %q", SpecVersion, h.SpecVersion)})
+	}
+	if h.Type == "" {
+		errs = append(errs, Finding{LintMissingType, "type", "type is required"})
+	}
+	if h.Source == "" {
+		errs = append(errs, Finding{LintMissingSource, "source", "source is required"})
+	}
+	if h.Subject == "" {
+		errs = append(errs, Finding{LintMissingSubject, "subject", "subject is required"})
+	} else if !isDIDString(h.Subject) {
+		warns = append(warns, Finding{LintNonDIDSubject, "subject", fmt.Sprintf("subject %q is not a DID", h.Subject)})
+	}
+	if h.ID == "" {
+		errs = append(errs, Finding{LintMissingID, "id", "id is required"})
+	}
+	if h.Time.IsZero() {
+		errs = append(errs, Finding{LintMissingTime, "time", "time is required"})
+	} else if h.Time.After(time.Now().Add(LintMaxClockSkew)) {
+		warns = append(warns, Finding{LintFutureTime, "time", fmt.Sprintf("time %s is in the future", h.Time.Format(time.RFC3339))})
+	}
+	if h.Producer == "" {
+		warns = append(warns, Finding{LintMissingProducer, "producer", "producer is empty"})
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(h.Extras)) {
+		if isReservedExtraKey(k) {
+			errs = append(errs, Finding{LintReservedExtraKey, k, fmt.Sprintf("extra %q collides with a reserved attribute", k)})
+		}
+		if strings.ToLower(k) != k {
+			warns = append(warns, Finding{LintUppercaseExtraKey, k, fmt.Sprintf("extra %q contains upper-case letters", k)})
+		}
+		if _, err := json.Marshal(h.Extras[k]); err != nil {
+			errs = append(errs, Finding{LintInvalidExtra, k, fmt.Sprintf("extra %q cannot be encoded: %v", k, err)})
+		}
+	}
+	if len(h.Extras) > 0 {
+		if encoded, err := json.Marshal(h.Extras); err == nil && len(encoded) > LintMaxExtrasSize {
+			warns = append(warns, Finding{LintOversizedExtras, "extras", fmt.Sprintf("extras are %d bytes, more than %d", len(encoded), LintMaxExtrasSize)})
+		}
+	}
+	return warns, errs
+}
+
+// isDIDString reports whether s has the generic did:<method>:<id> form.
+func isDIDString(s string) bool {
+	rest, ok := strings.CutPrefix(s, "did:")
+	if !ok {
+		return false
+	}
+	method, id, ok := strings.Cut(rest, ":")
+	return ok && method != "" && id != ""
+}
+
+// Validate checks the attributes CloudEvents 1.0 requires (id, source, type and specversion "1.0")
+// and the DIMO conventions (non-empty subject, non-zero time), as well as extras that collide with
+// header attributes or cannot be encoded. It returns every violation joined with errors.Join; each
+// one is a Finding carrying a Lint code. Lint warnings are not reported.
+func (c CloudEventHeader) Validate() error {
+	_, findings := Lint(c)
+	errs := make([]error, len(findings))
+	for i, f := range findings {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateWithData is Validate with the additional requirement that the event carries data:
+// a non-empty payload for RawEvent, or a non-nil value when A is a pointer, map, slice or interface.
+func (c CloudEvent[A]) ValidateWithData() error {
+	err := c.Validate()
+	if !c.hasData() {
+		err = errors.Join(err, Finding{LintMissingData, "data", "data is required"})
+	}
+	return err
+}
+
+func (c CloudEvent[A]) hasData() bool {
+	if raw, ok := (any)(c.Data).(json.RawMessage); ok {
+		return len(raw) > 0 || c.DataBase64 != ""
+	}
+	v := reflect.ValueOf(&c.Data).Elem()
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return !v.IsNil()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,132 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintFixture() cloudevent.CloudEventHeader {
+	return cloudevent.CloudEventHeader{
+		SpecVersion: cloudevent.SpecVersion,
+		Type:        cloudevent.TypeStatus,
+		Source:      "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		Subject:     "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+		ID:          "1",
+		Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Producer:    "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+		Extras:      map[string]any{"region": "eu"},
+	}
+}
+
+func codes(findings []cloudevent.Finding) []string {
+	out := []string{}
+	for _, f := range findings {
+		out = append(out, f.Code)
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		mutate   func(h *cloudevent.CloudEventHeader)
+		warnings []string
+		errors   []string
+	}{
+		{name: "clean", mutate: func(*cloudevent.CloudEventHeader) {}},
+		{name: "missing id", mutate: func(h *cloudevent.CloudEventHeader) { h.ID = "" }, errors: []string{"missing_id"}},
+		{name: "missing source", mutate: func(h *cloudevent.CloudEventHeader) { h.Source = "" }, errors: []string{"missing_source"}},
+		{name: "missing type", mutate: func(h *cloudevent.CloudEventHeader) { h.Type = "" }, errors: []string{"missing_type"}},
+		{name: "invalid specversion", mutate: func(h *cloudevent.CloudEventHeader) { h.SpecVersion = "0.3" }, errors: []string{"invalid_specversion"}},
+		{name: "missing specversion", mutate: func(h *cloudevent.CloudEventHeader) { h.SpecVersion = "" }, errors: []string{"invalid_specversion"}},
+		{name: "missing subject", mutate: func(h *cloudevent.CloudEventHeader) { h.Subject = "" }, errors: []string{"missing_subject"}},
+		{name: "missing time", mutate: func(h *cloudevent.CloudEventHeader) { h.Time = time.Time{} }, errors: []string{"missing_time"}},
+		{name: "reserved extra", mutate: func(h *cloudevent.CloudEventHeader) { h.Extras["data"] = 1 }, errors: []string{"reserved_extra_key"}},
+		{name: "invalid extra", mutate: func(h *cloudevent.CloudEventHeader) { h.Extras["bad"] = make(chan int) }, errors: []string{"invalid_extra"}},
+		{name: "missing producer", mutate: func(h *cloudevent.CloudEventHeader) { h.Producer = "" }, warnings: []string{"missing_producer"}},
+		{name: "non-DID subject", mutate: func(h *cloudevent.CloudEventHeader) { h.Subject = "vehicle-1" }, warnings: []string{"non_did_subject"}},
+		{name: "uppercase extra", mutate: func(h *cloudevent.CloudEventHeader) { h.Extras["deviceId"] = "x" }, warnings: []string{"uppercase_extra_key"}},
+		{name: "oversized extras", mutate: func(h *cloudevent.CloudEventHeader) {
+			h.Extras["dump"] = strings.Repeat("x", cloudevent.LintMaxExtrasSize)
+		}, warnings: []string{"oversized_extras"}},
+		{name: "future time", mutate: func(h *cloudevent.CloudEventHeader) { h.Time = time.Now().Add(time.Hour) }, warnings: []string{"future_time"}},
+		{name: "several", mutate: func(h *cloudevent.CloudEventHeader) {
+			h.ID = ""
+			h.Producer = ""
+			h.Extras["Region"] = "eu"
+		}, warnings: []string{"missing_producer", "uppercase_extra_key"}, errors: []string{"missing_id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := lintFixture()
+			tt.mutate(&h)
+			warnings, errs := cloudevent.Lint(h)
+			assert.Equal(t, append([]string{}, tt.warnings...), codes(warnings))
+			assert.Equal(t, append([]string{}, tt.errors...), codes(errs))
+		})
+	}
+}
+
+func TestFinding_String(t *testing.T) {
+	t.Parallel()
+
+	h := lintFixture()
+	h.ID = ""
+	_, errs := cloudevent.Lint(h)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "missing_id (id): id is required", errs[0].String())
+	assert.Equal(t, "cloudevent: missing_id (id): id is required", errs[0].Error())
+}
+
+func TestCloudEventHeader_Validate(t *testing.T) {
+	t.Parallel()
+
+	h := lintFixture()
+	h.Producer = ""
+	require.NoError(t, h.Validate(), "warnings are not validation errors")
+
+	err := cloudevent.CloudEventHeader{Extras: map[string]any{"id": "x"}}.Validate()
+	require.Error(t, err)
+	var got []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var f cloudevent.Finding
+		require.True(t, errors.As(e, &f))
+		got = append(got, f.Code)
+	}
+	assert.Equal(t, []string{"invalid_specversion", "missing_type", "missing_source", "missing_subject", "missing_id", "missing_time", "reserved_extra_key"}, got)
+}
+
+func TestCloudEvent_ValidateWithData(t *testing.T) {
+	t.Parallel()
+
+	raw := cloudevent.RawEvent{CloudEventHeader: lintFixture()}
+	err := raw.ValidateWithData()
+	var f cloudevent.Finding
+	require.True(t, errors.As(err, &f))
+	assert.Equal(t, "missing_data", f.Code)
+	require.NoError(t, raw.Validate())
+
+	raw.Data = json.RawMessage(`{}`)
+	require.NoError(t, raw.ValidateWithData())
+	raw.Data = nil
+	raw.DataBase64 = "e30="
+	require.NoError(t, raw.ValidateWithData())
+
+	ptr := cloudevent.CloudEvent[*struct{}]{CloudEventHeader: lintFixture()}
+	require.Error(t, ptr.ValidateWithData())
+	ptr.Data = &struct{}{}
+	require.NoError(t, ptr.ValidateWithData())
+
+	value := cloudevent.CloudEvent[struct{}]{CloudEventHeader: lintFixture()}
+	require.NoError(t, value.ValidateWithData())
+}
@@ -0,0 +1,160 @@
+// Package mqtt binds CloudEvents to MQTT v5 PUBLISH packets.
+//
+// Events are written in binary mode: header attributes and extras become user properties
+// using the flat string-map encoding of cloudevent.CloudEventHeader.ToStringMap, the data
+// content type goes into the content type property and Data is the payload. Messages without
+// a specversion user property are read as structured mode, with the whole event JSON in the payload.
+//
+// Message holds only the PUBLISH fields the binding uses, so it maps onto any MQTT v5 client's
+// packet type. PublishFromEvent and EventFromPublish convert directly to and from the paho.golang
+// client's paho.Publish.
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/eclipse/paho.golang/paho"
+)
+
+// StructuredContentType is the content type of a structured-mode message.
+const StructuredContentType = cloudevent.ContentTypeCloudEventsJSON
+
+// UserProperty is an MQTT v5 user property.
+type UserProperty struct {
+	Key   string
+	Value string
+}
+
+// Message is the part of an MQTT v5 PUBLISH packet that carries a CloudEvent.
+type Message struct {
+	Topic          string
+	ContentType    string
+	UserProperties []UserProperty
+	Payload        []byte
+}
+
+// FromEvent encodes ev as a binary-mode message published to topic.
+// User properties are sorted by key so the same event always produces the same message.
+func FromEvent(ev cloudevent.RawEvent, topic string) (Message, error) {
+	props, err := ev.ToStringMap()
+	if err != nil {
+		return Message{}, err
+	}
+	delete(props, "datacontenttype")
+	msg := Message{
+		Topic:          topic,
+		ContentType:    ev.DataContentType,
+		UserProperties: make([]UserProperty, 0, len(props)),
+		Payload:        ev.Data,
+	}
+	for k, v := range props {
+		msg.UserProperties = append(msg.UserProperties, UserProperty{Key: k, Value: v})
+	}
+	sort.Slice(msg.UserProperties, func(i, j int) bool {
+		return msg.UserProperties[i].Key < msg.UserProperties[j].Key
+	})
+	return msg, nil
+}
+
+// ToEvent decodes a message written in binary or structured mode.
+// A message is binary mode when it has a specversion user property; otherwise the payload
+// must be a structured-mode CloudEvent JSON document.
+func ToEvent(msg Message) (cloudevent.RawEvent, error) {
+	props := make(map[string]string, len(msg.UserProperties))
+	for _, p := range msg.UserProperties {
+		props[p.Key] = p.Value
+	}
+	if _, ok := props["specversion"]; !ok {
+		var ev cloudevent.RawEvent
+		if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+			return cloudevent.RawEvent{}, fmt.Errorf("failed to decode structured-mode payload: %w", err)
+		}
+		return ev, nil
+	}
+	hdr, err := cloudevent.HeaderFromStringMap(props)
+	if err != nil {
+		return cloudevent.RawEvent{}, err
+	}
+	hdr.DataContentType = msg.ContentType
+	return cloudevent.RawEvent{CloudEventHeader: hdr, Data: msg.Payload}, nil
+}
+
+// PublishFromEvent encodes ev as a binary-mode paho PUBLISH packet to topic. The content type
+// and user properties are set as by FromEvent; QoS, retain and other properties are left for
+// the caller to set.
+func PublishFromEvent(ev cloudevent.RawEvent, topic string) (*paho.Publish, error) {
+	msg, err := FromEvent(ev, topic)
+	if err != nil {
+		return nil, err
+	}
+	props := &paho.PublishProperties{
+		ContentType: msg.ContentType,
+		User:        make(paho.UserProperties, len(msg.UserProperties)),
+	}
+	for i, p := range msg.UserProperties {
+		props.User[i] = paho.UserProperty{Key: p.Key, Value: p.Value}
+	}
+	return &paho.Publish{Topic: msg.Topic, Properties: props, Payload: msg.Payload}, nil
+}
+
+// EventFromPublish decodes a paho PUBLISH packet written in binary or structured mode, as ToEvent does.
+func EventFromPublish(p *paho.Publish) (cloudevent.RawEvent, error) {
+	if p == nil {
+		return cloudevent.RawEvent{}, errors.New("nil publish packet")
+	}
+	msg := Message{Topic: p.Topic, Payload: p.Payload}
+	if p.Properties != nil {
+		msg.ContentType = p.Properties.ContentType
+		msg.UserProperties = make([]UserProperty, len(p.Properties.User))
+		for i, u := range p.Properties.User {
+			msg.UserProperties[i] = UserProperty{Key: u.Key, Value: u.Value}
+		}
+	}
+	return ToEvent(msg)
+}
+
+// topicFields are the placeholders Topic understands.
+var topicFields = map[string]func(cloudevent.CloudEventHeader) string{
+	"type":     func(h cloudevent.CloudEventHeader) string { return h.Type },
+	"source":   func(h cloudevent.CloudEventHeader) string { return h.Source },
+	"subject":  func(h cloudevent.CloudEventHeader) string { return h.Subject },
+	"producer": func(h cloudevent.CloudEventHeader) string { return h.Producer },
+	"id":       func(h cloudevent.CloudEventHeader) string { return h.ID },
+}
+
+// Topic builds a topic from template by replacing {type}, {source}, {subject}, {producer}
+// and {id} with the header's values, e.g. "events/{type}/{subject}".
+// Values that are empty or would add topic levels or wildcards ('/', '+', '#') are rejected.
+func Topic(template string, hdr cloudevent.CloudEventHeader) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", errors.New("unterminated placeholder in topic template")
+		}
+		name := rest[start+1 : start+end]
+		field, ok := topicFields[name]
+		if !ok {
+			return "", fmt.Errorf("unknown topic placeholder {%s}", name)
+		}
+		value := field(hdr)
+		if value == "" || strings.ContainsAny(value, "/+#\x00") {
+			return "", fmt.Errorf("invalid %s %q for a topic level", name, value)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(value)
+		rest = rest[start+end+1:]
+	}
+	return b.String(), nil
+}
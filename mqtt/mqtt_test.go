@@ -0,0 +1,136 @@
+package mqtt_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/DIMO-Network/cloudevent/mqtt"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() cloudevent.RawEvent {
+	return cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{
+			SpecVersion:     cloudevent.SpecVersion,
+			Type:            cloudevent.TypeStatus,
+			Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+			Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+			ID:              "abc",
+			Time:            time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			DataContentType: "application/json",
+			Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+			Extras:          map[string]any{"speed": float64(12)},
+		},
+		Data: json.RawMessage(`{"message":"hello"}`),
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ev := testEvent()
+	msg, err := mqtt.FromEvent(ev, "events/status")
+	require.NoError(t, err)
+	assert.Equal(t, "events/status", msg.Topic)
+	assert.Equal(t, "application/json", msg.ContentType)
+	assert.Equal(t, []byte(ev.Data), msg.Payload)
+	assert.Contains(t, msg.UserProperties, mqtt.UserProperty{Key: "type", Value: cloudevent.TypeStatus})
+	assert.Contains(t, msg.UserProperties, mqtt.UserProperty{Key: "ext-speed", Value: "12"})
+	assert.NotContains(t, msg.UserProperties, mqtt.UserProperty{Key: "datacontenttype", Value: "application/json"})
+	for i := 1; i < len(msg.UserProperties); i++ {
+		assert.Less(t, msg.UserProperties[i-1].Key, msg.UserProperties[i].Key)
+	}
+
+	// Binary-mode extras decode numbers as json.Number.
+	want := ev
+	want.Extras = map[string]any{"speed": json.Number("12")}
+	got, err := mqtt.ToEvent(msg)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestToEvent_Structured(t *testing.T) {
+	t.Parallel()
+
+	ev := testEvent()
+	payload, err := json.Marshal(ev)
+	require.NoError(t, err)
+
+	got, err := mqtt.ToEvent(mqtt.Message{ContentType: mqtt.StructuredContentType, Payload: payload})
+	require.NoError(t, err)
+	assert.Equal(t, ev, got)
+
+	// Unrelated user properties do not switch the message to binary mode.
+	got, err = mqtt.ToEvent(mqtt.Message{UserProperties: []mqtt.UserProperty{{Key: "trace", Value: "1"}}, Payload: payload})
+	require.NoError(t, err)
+	assert.Equal(t, ev, got)
+
+	_, err = mqtt.ToEvent(mqtt.Message{Payload: []byte("not json")})
+	require.Error(t, err)
+}
+
+func TestTopic(t *testing.T) {
+	t.Parallel()
+
+	hdr := testEvent().CloudEventHeader
+	topic, err := mqtt.Topic("events/{type}/{subject}", hdr)
+	require.NoError(t, err)
+	assert.Equal(t, "events/dimo.status/did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1", topic)
+
+	for _, template := range []string{"events/{unknown}", "events/{type", "events/{raweventid}"} {
+		_, err := mqtt.Topic(template, hdr)
+		assert.Error(t, err, template)
+	}
+
+	hdr.Source = "a/b"
+	_, err = mqtt.Topic("{source}", hdr)
+	require.Error(t, err)
+	hdr.Source = "#"
+	_, err = mqtt.Topic("{source}", hdr)
+	require.Error(t, err)
+	hdr.Source = ""
+	_, err = mqtt.Topic("{source}", hdr)
+	require.Error(t, err)
+}
+
+func TestPublish_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ev := testEvent()
+	pub, err := mqtt.PublishFromEvent(ev, "events/status")
+	require.NoError(t, err)
+	assert.Equal(t, "events/status", pub.Topic)
+	assert.Equal(t, []byte(ev.Data), pub.Payload)
+	require.NotNil(t, pub.Properties)
+	assert.Equal(t, "application/json", pub.Properties.ContentType)
+	assert.Contains(t, pub.Properties.User, paho.UserProperty{Key: "specversion", Value: "1.0"})
+	assert.Contains(t, pub.Properties.User, paho.UserProperty{Key: "ext-speed", Value: "12"})
+
+	// paho.UserProperties.Get reads the same values a subscriber would see.
+	assert.Equal(t, ev.ID, pub.Properties.User.Get("id"))
+
+	want := ev
+	want.Extras = map[string]any{"speed": json.Number("12")}
+	got, err := mqtt.EventFromPublish(pub)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestEventFromPublish_Structured(t *testing.T) {
+	t.Parallel()
+
+	ev := testEvent()
+	payload, err := json.Marshal(ev)
+	require.NoError(t, err)
+
+	got, err := mqtt.EventFromPublish(&paho.Publish{Topic: "events", Payload: payload})
+	require.NoError(t, err, "properties are optional")
+	assert.Equal(t, ev, got)
+
+	_, err = mqtt.EventFromPublish(nil)
+	require.Error(t, err)
+}
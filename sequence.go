@@ -0,0 +1,79 @@
+package cloudevent
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// SequenceExtraKey is the Extras key of the CloudEvents sequence extension.
+// SetSequence stores the value as a zero-padded 20-digit decimal string, so lexicographic
+// order, as the extension specifies, matches numeric order.
+const SequenceExtraKey = "sequence"
+
+// SetSequence sets the sequence extension on h.
+func SetSequence(h *CloudEventHeader, seq uint64) {
+	if h.Extras == nil {
+		h.Extras = make(map[string]any)
+	}
+	h.Extras[SequenceExtraKey] = fmt.Sprintf("%020d", seq)
+}
+
+// Sequence returns the sequence extension of h. Decimal strings, with or without padding,
+// and non-negative integral numbers of any Go or decoded JSON type are accepted.
+func Sequence(h CloudEventHeader) (uint64, bool) {
+	v, ok := h.Extras[SequenceExtraKey]
+	if !ok {
+		return 0, false
+	}
+	if s, ok := v.(string); ok {
+		seq, err := strconv.ParseUint(s, 10, 64)
+		return seq, err == nil
+	}
+	return extraUint64(v)
+}
+
+// CompareForReplay orders events for deterministic replay: by Time, then by Sequence, then by ID.
+// Events without a sequence sort before events with one at the same time.
+func CompareForReplay(a, b CloudEventHeader) int {
+	if c := a.Time.Compare(b.Time); c != 0 {
+		return c
+	}
+	seqA, okA := Sequence(a)
+	seqB, okB := Sequence(b)
+	switch {
+	case okA && !okB:
+		return 1
+	case !okA && okB:
+		return -1
+	case okA && okB:
+		if c := cmp.Compare(seqA, seqB); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// SortForReplay sorts events in place with CompareForReplay. The sort is stable.
+func SortForReplay[A any](events []CloudEvent[A]) {
+	slices.SortStableFunc(events, func(a, b CloudEvent[A]) int {
+		return CompareForReplay(a.CloudEventHeader, b.CloudEventHeader)
+	})
+}
+
+// DedupeForReplay returns events with repeated deliveries removed, keeping the first
+// occurrence of each Key. The order of the remaining events is unchanged.
+func DedupeForReplay[A any](events []CloudEvent[A]) []CloudEvent[A] {
+	seen := make(map[string]struct{}, len(events))
+	out := make([]CloudEvent[A], 0, len(events))
+	for _, ev := range events {
+		key := ev.Key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, ev)
+	}
+	return out
+}
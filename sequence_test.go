@@ -0,0 +1,90 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	var hdr cloudevent.CloudEventHeader
+	_, ok := cloudevent.Sequence(hdr)
+	assert.False(t, ok)
+
+	cloudevent.SetSequence(&hdr, 42)
+	assert.Equal(t, "00000000000000000042", hdr.Extras[cloudevent.SequenceExtraKey])
+
+	// The sequence survives a JSON round trip.
+	encoded, err := json.Marshal(hdr)
+	require.NoError(t, err)
+	var decoded cloudevent.CloudEventHeader
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	seq, ok := cloudevent.Sequence(decoded)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), seq)
+
+	// Producers that send a plain number are accepted.
+	decoded.Extras[cloudevent.SequenceExtraKey] = float64(7)
+	seq, ok = cloudevent.Sequence(decoded)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), seq)
+
+	for _, v := range []any{int64(7), uint32(7), json.Number("7")} {
+		decoded.Extras[cloudevent.SequenceExtraKey] = v
+		seq, ok = cloudevent.Sequence(decoded)
+		assert.True(t, ok, "%T", v)
+		assert.Equal(t, uint64(7), seq, "%T", v)
+	}
+
+	for _, bad := range []any{"abc", 1.5, float64(-1), int64(-1), json.Number("-1"), json.Number("1.5"), float64(1 << 64), math.Inf(1), math.NaN()} {
+		decoded.Extras[cloudevent.SequenceExtraKey] = bad
+		_, ok = cloudevent.Sequence(decoded)
+		assert.False(t, ok, "%v", bad)
+	}
+}
+
+func TestCompareForReplay(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := func(id string, ts time.Time, seq int) cloudevent.RawEvent {
+		ev := cloudevent.RawEvent{CloudEventHeader: cloudevent.CloudEventHeader{ID: id, Time: ts, Subject: "s"}}
+		if seq >= 0 {
+			cloudevent.SetSequence(&ev.CloudEventHeader, uint64(seq))
+		}
+		return ev
+	}
+
+	events := []cloudevent.RawEvent{
+		event("d", t0, 2),                   // same time, higher sequence
+		event("z", t0.Add(time.Second), -1), // later time wins over everything
+		event("c", t0, 1),                   // same time and sequence as b, ordered by ID
+		event("b", t0, 1),
+		event("a", t0, -1), // no sequence sorts first at the same time
+		event("b", t0, 1),  // duplicate delivery
+	}
+	sorted := append([]cloudevent.RawEvent(nil), events...)
+	cloudevent.SortForReplay(sorted)
+	var ids []string
+	for _, ev := range sorted {
+		ids = append(ids, ev.ID)
+	}
+	assert.Equal(t, []string{"a", "b", "b", "c", "d", "z"}, ids)
+
+	deduped := cloudevent.DedupeForReplay(sorted)
+	require.Len(t, deduped, 5)
+	assert.Equal(t, "c", deduped[2].ID)
+
+	assert.Equal(t, 0, cloudevent.CompareForReplay(events[3].CloudEventHeader, events[5].CloudEventHeader))
+	assert.Equal(t, -1, cloudevent.CompareForReplay(events[3].CloudEventHeader, events[2].CloudEventHeader))
+	assert.Equal(t, 1, cloudevent.CompareForReplay(events[0].CloudEventHeader, events[2].CloudEventHeader))
+	assert.Equal(t, -1, cloudevent.CompareForReplay(events[4].CloudEventHeader, events[3].CloudEventHeader))
+	assert.Equal(t, 1, cloudevent.CompareForReplay(events[1].CloudEventHeader, events[0].CloudEventHeader))
+}
@@ -0,0 +1,73 @@
+package cloudevent
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrMissingSignature is returned by VerifyEvent when the header has no Signature.
+var ErrMissingSignature = errors.New("cloudevent: event is not signed")
+
+// EventDigest returns the digest SignEvent signs: the EIP-191 personal message hash of
+// SignaturePayload(hdr, data), the canonical encoding without any signatures. The digest
+// does not depend on how the event was marshaled, and adding signatures does not change it. A
+// wallet or ethers.js signMessage over the same canonical bytes produces a compatible signature.
+func EventDigest(hdr CloudEventHeader, data []byte) ([]byte, error) {
+	payload, err := SignaturePayload(hdr, data)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(payload))
+	return ethcrypto.Keccak256([]byte(prefix), payload), nil
+}
+
+// SignEvent signs the header and data with key and stores the 65-byte [R || S || V] signature,
+// hex encoded with V as 27 or 28, in hdr.Signature. Any existing Signature is replaced.
+func SignEvent(hdr *CloudEventHeader, data []byte, key *ecdsa.PrivateKey) error {
+	digest, err := EventDigest(*hdr, data)
+	if err != nil {
+		return err
+	}
+	sig, err := ethcrypto.Sign(digest, key)
+	if err != nil {
+		return fmt.Errorf("cloudevent: signing event %q: %w", hdr.ID, err)
+	}
+	sig[ethcrypto.RecoveryIDOffset] += 27
+	hdr.Signature = hexutil.Encode(sig)
+	return nil
+}
+
+// VerifyEvent recovers the address that signed the header and data with SignEvent. It fails
+// with ErrMissingSignature if the header is unsigned, and with an error if the signature is
+// malformed. Any change to the data or to a signed attribute recovers a different address, so
+// callers must compare the result with the expected signer.
+func VerifyEvent(hdr *CloudEventHeader, data []byte) (common.Address, error) {
+	if hdr.Signature == "" {
+		return common.Address{}, ErrMissingSignature
+	}
+	sig, err := hexutil.Decode(hdr.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("cloudevent: invalid signature on event %q: %w", hdr.ID, err)
+	}
+	if len(sig) != ethcrypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("cloudevent: invalid signature on event %q: got %d bytes, expected %d", hdr.ID, len(sig), ethcrypto.SignatureLength)
+	}
+	if sig[ethcrypto.RecoveryIDOffset] >= 27 {
+		sig[ethcrypto.RecoveryIDOffset] -= 27
+	}
+	digest, err := EventDigest(*hdr, data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := ethcrypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("cloudevent: invalid signature on event %q: %w", hdr.ID, err)
+	}
+	return ethcrypto.PubkeyToAddress(*pub), nil
+}
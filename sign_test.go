@@ -0,0 +1,124 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedFixture(t *testing.T) (cloudevent.CloudEventHeader, []byte) {
+	t.Helper()
+	return cloudevent.CloudEventHeader{
+		SpecVersion: cloudevent.SpecVersion,
+		Type:        cloudevent.TypeStatus,
+		Source:      "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		Subject:     "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+		ID:          "1",
+		Time:        time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Extras:      map[string]any{"region": "eu", "meta": map[string]any{"b": 1, "a": 2}},
+	}, []byte(`{"speed":42}`)
+}
+
+func TestSignEvent(t *testing.T) {
+	t.Parallel()
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	want := ethcrypto.PubkeyToAddress(key.PublicKey)
+
+	hdr, data := signedFixture(t)
+	require.NoError(t, cloudevent.SignEvent(&hdr, data, key))
+	assert.Len(t, hdr.Signature, 2+65*2)
+
+	got, err := cloudevent.VerifyEvent(&hdr, data)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The signature survives a JSON round trip and an equivalent time in another zone.
+	encoded, err := json.Marshal(cloudevent.RawEvent{CloudEventHeader: hdr, Data: data})
+	require.NoError(t, err)
+	var decoded cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	decoded.Time = decoded.Time.In(time.FixedZone("UTC+2", 2*60*60))
+	got, err = cloudevent.VerifyEvent(&decoded.CloudEventHeader, decoded.Data)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Adding further signatures does not invalidate the event signature.
+	require.NoError(t, cloudevent.AddSignature(&hdr, cloudevent.SignatureEntry{Algorithm: "x", Signer: "y", Signature: "z"}))
+	got, err = cloudevent.VerifyEvent(&hdr, data)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestVerifyEvent_Tampered(t *testing.T) {
+	t.Parallel()
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	want := ethcrypto.PubkeyToAddress(key.PublicKey)
+	hdr, data := signedFixture(t)
+	require.NoError(t, cloudevent.SignEvent(&hdr, data, key))
+
+	got, err := cloudevent.VerifyEvent(&hdr, []byte(`{"speed":43}`))
+	if err == nil {
+		assert.NotEqual(t, want, got, "tampered data")
+	}
+
+	for name, mutate := range map[string]func(h *cloudevent.CloudEventHeader){
+		"subject": func(h *cloudevent.CloudEventHeader) { h.Subject += "0" },
+		"time":    func(h *cloudevent.CloudEventHeader) { h.Time = h.Time.Add(time.Nanosecond) },
+		"extra":   func(h *cloudevent.CloudEventHeader) { h.Extras = map[string]any{"region": "us"} },
+	} {
+		tampered := hdr.Clone()
+		mutate(&tampered)
+		got, err := cloudevent.VerifyEvent(&tampered, data)
+		if err == nil {
+			assert.NotEqual(t, want, got, name)
+		}
+	}
+
+	unsigned, _ := signedFixture(t)
+	_, err = cloudevent.VerifyEvent(&unsigned, data)
+	require.ErrorIs(t, err, cloudevent.ErrMissingSignature)
+
+	unsigned.Signature = "0x1234"
+	_, err = cloudevent.VerifyEvent(&unsigned, data)
+	require.Error(t, err)
+	unsigned.Signature = "not hex"
+	_, err = cloudevent.VerifyEvent(&unsigned, data)
+	require.Error(t, err)
+}
+
+func TestEventDigest_LargeIntegerExtras(t *testing.T) {
+	t.Parallel()
+
+	hdr, data := signedFixture(t)
+	hdr.Extras = map[string]any{"vehicletokenid": json.Number("18446744073709551615")}
+	other := hdr.Clone()
+	other.Extras["vehicletokenid"] = json.Number("18446744073709551614")
+
+	digest, err := cloudevent.EventDigest(hdr, data)
+	require.NoError(t, err)
+	otherDigest, err := cloudevent.EventDigest(other, data)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, otherDigest)
+
+	canonical, err := cloudevent.CanonicalJSON(cloudevent.RawEvent{CloudEventHeader: hdr, Data: data})
+	require.NoError(t, err)
+	assert.Contains(t, string(canonical), `"vehicletokenid":18446744073709551615`)
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, cloudevent.SignEvent(&hdr, data, key))
+	other.Signature = hdr.Signature
+	got, err := cloudevent.VerifyEvent(&other, data)
+	if err == nil {
+		assert.NotEqual(t, ethcrypto.PubkeyToAddress(key.PublicKey), got)
+	}
+}
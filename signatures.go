@@ -0,0 +1,164 @@
+package cloudevent
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// SignaturesExtraKey is the Extras key holding the signatures added with AddSignature.
+// Its value is a JSON array of objects with "algorithm", "signer" and "signature" members.
+const SignaturesExtraKey = "signatures"
+
+// SignatureEntry is one signature over an event's SignaturePayload.
+type SignatureEntry struct {
+	// Algorithm names the signature scheme. It is empty for the legacy Signature field.
+	Algorithm string `json:"algorithm"`
+	// Signer identifies the key that produced the signature, e.g. an address or DID.
+	// It is empty for the legacy Signature field.
+	Signer string `json:"signer"`
+	// Signature is the encoded signature.
+	Signature string `json:"signature"`
+}
+
+// SignaturePolicy decides how many signatures VerifySignatures requires to be valid.
+type SignaturePolicy int
+
+const (
+	// RequireAllSignatures requires every signature on the event to verify.
+	RequireAllSignatures SignaturePolicy = iota
+	// RequireAnySignature requires at least one signature on the event to verify.
+	RequireAnySignature
+)
+
+// SignaturePayload returns the bytes every signature on an event covers: the CanonicalJSON
+// encoding of the header and data without the Signature attribute and the SignaturesExtraKey
+// extra. Adding a signature therefore never changes what the other signatures cover. SignEvent
+// signs the EIP-191 hash of these bytes, see EventDigest.
+func SignaturePayload(hdr CloudEventHeader, data []byte) ([]byte, error) {
+	if _, ok := hdr.Extras[SignaturesExtraKey]; ok {
+		hdr.Extras = maps.Clone(hdr.Extras)
+		delete(hdr.Extras, SignaturesExtraKey)
+	}
+	return CanonicalJSON(RawEvent{CloudEventHeader: hdr, Data: data})
+}
+
+// SignatureVerifier checks a single signature over payload and returns an error if it is invalid.
+type SignatureVerifier func(entry SignatureEntry, payload []byte) error
+
+// AddSignature appends sig to the signatures stored under SignaturesExtraKey.
+// The legacy Signature field is left untouched.
+func AddSignature(h *CloudEventHeader, sig SignatureEntry) error {
+	entries, err := extraSignatures(h)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, sig)
+	list := make([]any, len(entries))
+	for i, e := range entries {
+		list[i] = map[string]any{"algorithm": e.Algorithm, "signer": e.Signer, "signature": e.Signature}
+	}
+	if h.Extras == nil {
+		h.Extras = make(map[string]any)
+	}
+	h.Extras[SignaturesExtraKey] = list
+	return nil
+}
+
+// Signatures returns every signature on the event. A non-empty legacy Signature field comes first,
+// followed by the entries under SignaturesExtraKey. Malformed entries are skipped; VerifySignatures
+// rejects them instead.
+func Signatures(h *CloudEventHeader) []SignatureEntry {
+	var sigs []SignatureEntry
+	if h.Signature != "" {
+		sigs = append(sigs, SignatureEntry{Signature: h.Signature})
+	}
+	list, _ := h.Extras[SignaturesExtraKey].([]any)
+	for _, item := range list {
+		if entry, err := signatureEntry(item); err == nil {
+			sigs = append(sigs, entry)
+		}
+	}
+	return sigs
+}
+
+// VerifySignatures checks the signatures on ev over its SignaturePayload according to policy.
+// It fails if the event has no signatures or any entry under SignaturesExtraKey is malformed.
+func VerifySignatures(ev RawEvent, policy SignaturePolicy, verify SignatureVerifier) error {
+	entries, err := extraSignatures(&ev.CloudEventHeader)
+	if err != nil {
+		return err
+	}
+	if ev.Signature != "" {
+		entries = append([]SignatureEntry{{Signature: ev.Signature}}, entries...)
+	}
+	if len(entries) == 0 {
+		return errors.New("cloudevent: event has no signatures")
+	}
+
+	payload, err := SignaturePayload(ev.CloudEventHeader, ev.Data)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, entry := range entries {
+		if err := verify(entry, payload); err != nil {
+			errs = append(errs, fmt.Errorf("signature %d by %q: %w", i, entry.Signer, err))
+		}
+	}
+	switch policy {
+	case RequireAllSignatures:
+		if len(errs) > 0 {
+			return fmt.Errorf("cloudevent: invalid signatures: %w", errors.Join(errs...))
+		}
+	case RequireAnySignature:
+		if len(errs) == len(entries) {
+			return fmt.Errorf("cloudevent: no valid signature: %w", errors.Join(errs...))
+		}
+	default:
+		return fmt.Errorf("cloudevent: unknown signature policy %d", policy)
+	}
+	return nil
+}
+
+// extraSignatures parses the entries under SignaturesExtraKey.
+func extraSignatures(h *CloudEventHeader) ([]SignatureEntry, error) {
+	raw, ok := h.Extras[SignaturesExtraKey]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cloudevent: %s extra must be an array, got %T", SignaturesExtraKey, raw)
+	}
+	entries := make([]SignatureEntry, 0, len(list))
+	for i, item := range list {
+		entry, err := signatureEntry(item)
+		if err != nil {
+			return nil, fmt.Errorf("cloudevent: %s[%d]: %w", SignaturesExtraKey, i, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func signatureEntry(item any) (SignatureEntry, error) {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return SignatureEntry{}, fmt.Errorf("entry must be an object, got %T", item)
+	}
+	var entry SignatureEntry
+	for key, dst := range map[string]*string{"algorithm": &entry.Algorithm, "signer": &entry.Signer, "signature": &entry.Signature} {
+		if v, ok := obj[key]; ok {
+			s, ok := v.(string)
+			if !ok {
+				return SignatureEntry{}, fmt.Errorf("%s must be a string, got %T", key, v)
+			}
+			*dst = s
+		}
+	}
+	if entry.Signature == "" {
+		return SignatureEntry{}, errors.New("signature is empty")
+	}
+	return entry, nil
+}
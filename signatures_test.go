@@ -0,0 +1,164 @@
+package cloudevent_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSigner struct {
+	name string
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestSigner(t *testing.T, name string) testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return testSigner{name: name, pub: pub, priv: priv}
+}
+
+// sign signs the SignaturePayload of ev.
+func (s testSigner) sign(t *testing.T, ev cloudevent.RawEvent) cloudevent.SignatureEntry {
+	t.Helper()
+	payload, err := cloudevent.SignaturePayload(ev.CloudEventHeader, ev.Data)
+	require.NoError(t, err)
+	return cloudevent.SignatureEntry{Algorithm: "EdDSA", Signer: s.name, Signature: hex.EncodeToString(ed25519.Sign(s.priv, payload))}
+}
+
+// verifierFor checks ed25519 signatures by signer name; the legacy entry belongs to legacy.
+func verifierFor(legacy testSigner, signers ...testSigner) cloudevent.SignatureVerifier {
+	keys := map[string]ed25519.PublicKey{"": legacy.pub}
+	for _, s := range signers {
+		keys[s.name] = s.pub
+	}
+	return func(entry cloudevent.SignatureEntry, payload []byte) error {
+		sig, err := hex.DecodeString(entry.Signature)
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(keys[entry.Signer], payload, sig) {
+			return errors.New("bad signature")
+		}
+		return nil
+	}
+}
+
+func TestSignatures_MixedLegacyAndNew(t *testing.T) {
+	t.Parallel()
+
+	device := newTestSigner(t, "device")
+	oracle := newTestSigner(t, "oracle")
+	ev := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "1", Type: cloudevent.TypeAttestation},
+		Data:             json.RawMessage(`{"odometer":1000}`),
+	}
+	ev.Signature = device.sign(t, ev).Signature
+	require.NoError(t, cloudevent.AddSignature(&ev.CloudEventHeader, oracle.sign(t, ev)))
+
+	// The signatures survive a JSON round trip.
+	encoded, err := json.Marshal(ev)
+	require.NoError(t, err)
+	var decoded cloudevent.RawEvent
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+
+	sigs := cloudevent.Signatures(&decoded.CloudEventHeader)
+	require.Len(t, sigs, 2)
+	assert.Equal(t, cloudevent.SignatureEntry{Signature: ev.Signature}, sigs[0], "legacy signature comes first")
+	assert.Equal(t, "oracle", sigs[1].Signer)
+
+	verify := verifierFor(device, oracle)
+	require.NoError(t, cloudevent.VerifySignatures(decoded, cloudevent.RequireAllSignatures, verify))
+	require.NoError(t, cloudevent.VerifySignatures(decoded, cloudevent.RequireAnySignature, verify))
+}
+
+func TestVerifySignatures_Policies(t *testing.T) {
+	t.Parallel()
+
+	device := newTestSigner(t, "device")
+	oracle := newTestSigner(t, "oracle")
+	impostor := newTestSigner(t, "oracle")
+	ev := cloudevent.RawEvent{
+		CloudEventHeader: cloudevent.CloudEventHeader{ID: "1", Subject: "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1"},
+		Data:             json.RawMessage(`{"odometer":1000}`),
+	}
+	require.NoError(t, cloudevent.AddSignature(&ev.CloudEventHeader, device.sign(t, ev)))
+	require.NoError(t, cloudevent.AddSignature(&ev.CloudEventHeader, impostor.sign(t, ev)))
+
+	verify := verifierFor(testSigner{}, device, oracle)
+	require.Error(t, cloudevent.VerifySignatures(ev, cloudevent.RequireAllSignatures, verify))
+	require.NoError(t, cloudevent.VerifySignatures(ev, cloudevent.RequireAnySignature, verify))
+
+	// Tampering with the header or the data invalidates every signature.
+	retargeted := ev
+	retargeted.Subject = "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2"
+	require.Error(t, cloudevent.VerifySignatures(retargeted, cloudevent.RequireAnySignature, verify))
+	ev.Data = json.RawMessage(`{"odometer":1}`)
+	require.Error(t, cloudevent.VerifySignatures(ev, cloudevent.RequireAnySignature, verify))
+}
+
+func TestVerifySignatures_SignEventAndAddSignature(t *testing.T) {
+	t.Parallel()
+
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	oracle := newTestSigner(t, "oracle")
+	hdr, data := signedFixture(t)
+	require.NoError(t, cloudevent.SignEvent(&hdr, data, key))
+	ev := cloudevent.RawEvent{CloudEventHeader: hdr, Data: data}
+	require.NoError(t, cloudevent.AddSignature(&ev.CloudEventHeader, oracle.sign(t, ev)))
+
+	// The legacy entry is the SignEvent signature, an EIP-191 signature over the same payload.
+	eddsa := verifierFor(testSigner{}, oracle)
+	verify := func(entry cloudevent.SignatureEntry, payload []byte) error {
+		if entry.Signer != "" {
+			return eddsa(entry, payload)
+		}
+		sig, err := hexutil.Decode(entry.Signature)
+		if err != nil {
+			return err
+		}
+		sig[ethcrypto.RecoveryIDOffset] -= 27
+		pub, err := ethcrypto.SigToPub(ethcrypto.Keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(payload))), payload), sig)
+		if err != nil {
+			return err
+		}
+		if ethcrypto.PubkeyToAddress(*pub) != ethcrypto.PubkeyToAddress(key.PublicKey) {
+			return errors.New("wrong signer")
+		}
+		return nil
+	}
+	require.NoError(t, cloudevent.VerifySignatures(ev, cloudevent.RequireAllSignatures, verify))
+
+	addr, err := cloudevent.VerifyEvent(&ev.CloudEventHeader, ev.Data)
+	require.NoError(t, err)
+	assert.Equal(t, ethcrypto.PubkeyToAddress(key.PublicKey), addr)
+}
+
+func TestVerifySignatures_Invalid(t *testing.T) {
+	t.Parallel()
+
+	accept := func(cloudevent.SignatureEntry, []byte) error { return nil }
+
+	require.Error(t, cloudevent.VerifySignatures(cloudevent.RawEvent{}, cloudevent.RequireAnySignature, accept), "unsigned event")
+
+	malformed := cloudevent.RawEvent{CloudEventHeader: cloudevent.CloudEventHeader{
+		Signature: "0x1",
+		Extras:    map[string]any{cloudevent.SignaturesExtraKey: []any{map[string]any{"signer": "oracle"}}},
+	}}
+	require.Error(t, cloudevent.VerifySignatures(malformed, cloudevent.RequireAnySignature, accept))
+	assert.Len(t, cloudevent.Signatures(&malformed.CloudEventHeader), 1, "malformed entries are skipped")
+
+	notArray := cloudevent.CloudEventHeader{Extras: map[string]any{cloudevent.SignaturesExtraKey: "0x1"}}
+	require.Error(t, cloudevent.AddSignature(&notArray, cloudevent.SignatureEntry{Signature: "0x2"}))
+}
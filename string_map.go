@@ -0,0 +1,157 @@
+package cloudevent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// StringMapExtrasPrefix is prepended to the names of Extras in the flat string-map encoding.
+const StringMapExtrasPrefix = "ext-"
+
+// ToStringMap encodes the header as a flat map of strings for property-based transports
+// such as MQTT user properties, SQS message attributes or HTTP headers.
+//
+// Attributes use their JSON names (id, source, time, ...) and are omitted when empty.
+// time is formatted as RFC 3339 with nanoseconds. tags are joined with commas, with
+// commas and backslashes inside a tag escaped by a backslash. Each extra is stored
+// under StringMapExtrasPrefix plus its name, with its value encoded as JSON so that
+// strings, numbers, booleans and nested values all survive the round trip. Extras named
+// like a header attribute or data are skipped, as in MarshalJSON.
+func (c CloudEventHeader) ToStringMap() (map[string]string, error) {
+	m := map[string]string{"specversion": SpecVersion}
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	set("type", c.Type)
+	set("source", c.Source)
+	set("subject", c.Subject)
+	set("id", c.ID)
+	if !c.Time.IsZero() {
+		m["time"] = c.Time.Format(time.RFC3339Nano)
+	}
+	set("datacontenttype", c.DataContentType)
+	set("dataschema", c.DataSchema)
+	set("dataversion", c.DataVersion)
+	set("producer", c.Producer)
+	set("signature", c.Signature)
+	set("raweventid", c.RawEventID)
+	if len(c.Tags) > 0 {
+		m["tags"] = joinTags(c.Tags)
+	}
+	for k, v := range c.Extras {
+		if isReservedExtraKey(k) {
+			continue
+		}
+		vb, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cloudevent: invalid extra %q: %w", k, err)
+		}
+		m[StringMapExtrasPrefix+k] = string(vb)
+	}
+	return m, nil
+}
+
+// HeaderFromStringMap decodes a header encoded with ToStringMap.
+// Keys that are neither header attributes nor prefixed extras are ignored, so transport
+// specific properties can share the map. Extras decode as with UnmarshalJSON and WithUseNumber,
+// with numbers as json.Number, so integers beyond 2^53 keep their exact value. An extra named
+// like a header attribute or data is rejected, since the encoders would drop it.
+func HeaderFromStringMap(m map[string]string) (CloudEventHeader, error) {
+	header := CloudEventHeader{
+		SpecVersion:     SpecVersion,
+		Type:            m["type"],
+		Source:          m["source"],
+		Subject:         m["subject"],
+		ID:              m["id"],
+		DataContentType: m["datacontenttype"],
+		DataSchema:      m["dataschema"],
+		DataVersion:     m["dataversion"],
+		Producer:        m["producer"],
+		Signature:       m["signature"],
+		RawEventID:      m["raweventid"],
+	}
+	if ts, ok := m["time"]; ok {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return CloudEventHeader{}, fmt.Errorf("cloudevent: invalid time: %w", err)
+		}
+		header.Time = t
+	}
+	if tags, ok := m["tags"]; ok {
+		header.Tags = splitTags(tags)
+	}
+	for k, v := range m {
+		name, ok := strings.CutPrefix(k, StringMapExtrasPrefix)
+		if !ok {
+			continue
+		}
+		if isReservedExtraKey(name) {
+			return CloudEventHeader{}, fmt.Errorf("cloudevent: extra %q collides with a reserved attribute", name)
+		}
+		value, err := decodeStringMapExtra(v)
+		if err != nil {
+			return CloudEventHeader{}, fmt.Errorf("cloudevent: invalid extra %q: %w", name, err)
+		}
+		if header.Extras == nil {
+			header.Extras = make(map[string]any)
+		}
+		header.Extras[name] = value
+	}
+	return header, nil
+}
+
+// decodeStringMapExtra decodes a single JSON value with numbers as json.Number.
+func decodeStringMapExtra(v string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+// joinTags joins tags with commas, escaping commas and backslashes inside each tag.
+func joinTags(tags []string) string {
+	var b strings.Builder
+	for i, tag := range tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		for j := 0; j < len(tag); j++ {
+			if tag[j] == ',' || tag[j] == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(tag[j])
+		}
+	}
+	return b.String()
+}
+
+// splitTags reverses joinTags.
+func splitTags(s string) []string {
+	tags := []string{}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case s[i] == ',':
+			tags = append(tags, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(tags, b.String())
+}
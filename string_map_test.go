@@ -0,0 +1,128 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderStringMap_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	hdr := cloudevent.CloudEventHeader{
+		SpecVersion:     cloudevent.SpecVersion,
+		Type:            cloudevent.TypeStatus,
+		Source:          "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		Subject:         "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:1",
+		ID:              "abc",
+		Time:            time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC),
+		DataContentType: "application/json",
+		DataSchema:      "https://example.com/schema",
+		DataVersion:     "v2",
+		Producer:        "did:erc721:1:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:2",
+		Signature:       "0xdeadbeef",
+		RawEventID:      "raw-1",
+		Tags:            []string{"plain", "with,comma", `back\slash`, ""},
+		Extras: map[string]any{
+			"count":   float64(42),
+			"ratio":   1.5,
+			"enabled": true,
+			"label":   "true",
+			"nested":  map[string]any{"a": []any{"b", float64(1)}},
+			"nothing": nil,
+		},
+	}
+
+	m, err := hdr.ToStringMap()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-01T12:00:00.123456789Z", m["time"])
+	assert.Equal(t, `plain,with\,comma,back\\slash,`, m["tags"])
+	assert.Equal(t, "42", m["ext-count"])
+	assert.Equal(t, `"true"`, m["ext-label"])
+
+	// Numbers come back as json.Number.
+	want := hdr.Clone()
+	want.Extras["count"] = json.Number("42")
+	want.Extras["ratio"] = json.Number("1.5")
+	want.Extras["nested"] = map[string]any{"a": []any{"b", json.Number("1")}}
+	got, err := cloudevent.HeaderFromStringMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestHeaderStringMap_LargeIntegerExtra(t *testing.T) {
+	t.Parallel()
+
+	hdr := cloudevent.CloudEventHeader{
+		SpecVersion: cloudevent.SpecVersion,
+		ID:          "abc",
+		Extras:      map[string]any{"vehicletokenid": json.Number("18446744073709551615")},
+	}
+	m, err := hdr.ToStringMap()
+	require.NoError(t, err)
+	assert.Equal(t, "18446744073709551615", m["ext-vehicletokenid"])
+
+	got, err := cloudevent.HeaderFromStringMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, hdr, got)
+	tokenID, ok := cloudevent.GetExtra[uint64](got, "vehicletokenid")
+	require.True(t, ok)
+	assert.Equal(t, uint64(math.MaxUint64), tokenID)
+}
+
+func TestHeaderStringMap_ReservedExtra(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []string{"ext-id", "ext-data", "ext-data_base64", "ext-specversion"} {
+		_, err := cloudevent.HeaderFromStringMap(map[string]string{"id": "abc", key: `"x"`})
+		require.ErrorContains(t, err, "reserved", key)
+	}
+
+	// The encoder skips them, so every map it produces decodes.
+	hdr := cloudevent.CloudEventHeader{ID: "abc", Extras: map[string]any{"id": "fake", "data": "fake", "region": "eu"}}
+	m, err := hdr.ToStringMap()
+	require.NoError(t, err)
+	assert.NotContains(t, m, "ext-id")
+	assert.NotContains(t, m, "ext-data")
+	got, err := cloudevent.HeaderFromStringMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"region": "eu"}, got.Extras)
+}
+
+func TestHeaderStringMap_OmitsEmpty(t *testing.T) {
+	t.Parallel()
+
+	m, err := cloudevent.CloudEventHeader{ID: "abc"}.ToStringMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"specversion": "1.0", "id": "abc"}, m)
+
+	got, err := cloudevent.HeaderFromStringMap(m)
+	require.NoError(t, err)
+	assert.Equal(t, cloudevent.CloudEventHeader{SpecVersion: "1.0", ID: "abc"}, got)
+}
+
+func TestHeaderStringMap_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := cloudevent.CloudEventHeader{Extras: map[string]any{"bad": math.NaN()}}.ToStringMap()
+	require.Error(t, err)
+
+	_, err = cloudevent.HeaderFromStringMap(map[string]string{"time": "yesterday"})
+	require.Error(t, err)
+
+	_, err = cloudevent.HeaderFromStringMap(map[string]string{"ext-label": "unquoted"})
+	require.Error(t, err)
+
+	_, err = cloudevent.HeaderFromStringMap(map[string]string{"ext-label": `"a" "b"`})
+	require.Error(t, err)
+
+	// Transport properties without the extras prefix are ignored.
+	got, err := cloudevent.HeaderFromStringMap(map[string]string{"id": "abc", "x-request-id": "1"})
+	require.NoError(t, err)
+	assert.Nil(t, got.Extras)
+}
@@ -0,0 +1,83 @@
+package cloudevent
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// stringFieldMax is the length above which String abbreviates a field.
+const stringFieldMax = 32
+
+// String returns a compact one-line summary for logs, e.g.
+//
+//	dimo.status id=abc src=0xb57d6d57fca59d0517..1b071fa679 subj=did:erc721:137:0xbA5..c9B0cF:123 t=2024-05-01T10:00:00Z dv=vss/1.0
+//
+// Long values are shortened in the middle. Signature and extras values are never printed.
+func (c CloudEventHeader) String() string {
+	var b strings.Builder
+	c.writeSummary(&b)
+	return b.String()
+}
+
+// String returns the header summary followed by the data size when A is json.RawMessage.
+// Data itself is never printed.
+func (c CloudEvent[A]) String() string {
+	var b strings.Builder
+	c.writeSummary(&b)
+	if raw, ok := any(c.Data).(json.RawMessage); ok {
+		b.WriteString(" data=")
+		b.WriteString(strconv.Itoa(len(raw)))
+		b.WriteByte('B')
+	}
+	return b.String()
+}
+
+func (c CloudEventHeader) writeSummary(b *strings.Builder) {
+	if c.Type == "" {
+		b.WriteString("(no type)")
+	} else {
+		b.WriteString(abbreviate(c.Type))
+	}
+	writeSummaryField(b, "id", c.ID)
+	writeSummaryField(b, "src", c.Source)
+	writeSummaryField(b, "subj", c.Subject)
+	if !c.Time.IsZero() {
+		writeSummaryField(b, "t", c.Time.Format(time.RFC3339Nano))
+	}
+	writeSummaryField(b, "dv", c.DataVersion)
+	writeSummaryField(b, "prod", c.Producer)
+	if len(c.Extras) > 0 {
+		writeSummaryField(b, "extras", strconv.Itoa(len(c.Extras)))
+	}
+}
+
+func writeSummaryField(b *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	b.WriteByte(' ')
+	b.WriteString(name)
+	b.WriteByte('=')
+	b.WriteString(abbreviate(value))
+}
+
+// abbreviate shortens s to stringFieldMax bytes by replacing its middle with "..".
+// Both ends are kept because DIDs and addresses are told apart by their prefix and suffix.
+// The cuts fall on rune boundaries, so multi-byte characters are dropped whole rather than split.
+func abbreviate(s string) string {
+	if len(s) <= stringFieldMax {
+		return s
+	}
+	head := (stringFieldMax - 2) * 2 / 3
+	tail := len(s) - (stringFieldMax - 2 - head)
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return s[:head] + ".." + s[tail:]
+}
@@ -0,0 +1,51 @@
+package cloudevent_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DIMO-Network/cloudevent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudEventHeader_String(t *testing.T) {
+	t.Parallel()
+
+	h := cloudevent.CloudEventHeader{
+		Type:        cloudevent.TypeStatus,
+		ID:          "abc",
+		Source:      "0xb57d6d57fca59d0517038c968a1b831b071fa679",
+		Subject:     "did:erc721:137:0xbA5738a18d83D41847dfFbDC6101d37C69c9B0cF:123",
+		Time:        time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		DataVersion: "vss/1.0",
+		Signature:   "0xsecret",
+		Extras:      map[string]any{"region": "eu"},
+	}
+	want := "dimo.status id=abc src=0xb57d6d57fca59d0517..1b071fa679 subj=did:erc721:137:0xbA5..c9B0cF:123 t=2024-05-01T10:00:00Z dv=vss/1.0 extras=1"
+	assert.Equal(t, want, h.String())
+	assert.Equal(t, want, fmt.Sprint(h))
+	assert.NotContains(t, h.String(), "secret")
+	assert.NotContains(t, h.String(), "eu")
+
+	assert.Equal(t, "(no type)", cloudevent.CloudEventHeader{}.String())
+
+	ev := cloudevent.RawEvent{CloudEventHeader: h, Data: json.RawMessage(`{"speed":42}`)}
+	assert.Equal(t, want+" data=12B", ev.String())
+
+	typed := cloudevent.CloudEvent[TestData]{CloudEventHeader: h, Data: TestData{Message: "hidden"}}
+	assert.Equal(t, want, typed.String())
+}
+
+func TestCloudEventHeader_StringMultiByte(t *testing.T) {
+	t.Parallel()
+
+	// 41 bytes whose naive cut points fall inside a two-byte rune.
+	h := cloudevent.CloudEventHeader{Type: cloudevent.TypeStatus, Source: "a" + strings.Repeat("é", 20)}
+	got := h.String()
+	assert.True(t, utf8.ValidString(got), got)
+	assert.Equal(t, "dimo.status src=a"+strings.Repeat("é", 9)+".."+strings.Repeat("é", 5), got)
+}